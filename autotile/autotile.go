@@ -0,0 +1,162 @@
+// Package autotile resolves the terrains painted onto grid cells to the tile
+// identifiers of matching transition tiles.
+package autotile
+
+import (
+	"fmt"
+
+	"github.com/mewmew/pgg/grid"
+	"github.com/mewmew/pgg/tileset"
+)
+
+// A Terrain identifies a kind of terrain, such as water or grass. The zero value
+// represents no terrain.
+type Terrain int
+
+// A Direction specifies the direction from a cell to one of its eight
+// neighbours. The directions are ordered clockwise starting at the top, which
+// is the order of the colors of Tiled Wang identifiers.
+type Direction int
+
+// Directions.
+const (
+	North Direction = iota
+	NorthEast
+	East
+	SouthEast
+	South
+	SouthWest
+	West
+	NorthWest
+)
+
+// deltas maps from directions to column and row deltas.
+var deltas = [8]grid.Location{
+	North:     {Col: 0, Row: -1},
+	NorthEast: {Col: 1, Row: -1},
+	East:      {Col: 1, Row: 0},
+	SouthEast: {Col: 1, Row: 1},
+	South:     {Col: 0, Row: 1},
+	SouthWest: {Col: -1, Row: 1},
+	West:      {Col: -1, Row: 0},
+	NorthWest: {Col: -1, Row: -1},
+}
+
+// IsCorner returns true if the direction is diagonal.
+func (dir Direction) IsCorner() bool {
+	return dir%2 == 1
+}
+
+// A Neighborhood holds the terrain of a cell and of its eight neighbours.
+// Neighbours outside of the map have the terrain of the cell itself, so that
+// no transitions are resolved at the edges of the map.
+type Neighborhood struct {
+	// Terrain of the cell.
+	Center Terrain
+	// Terrain of the neighbouring cells, indexed by direction.
+	Neighbors [8]Terrain
+}
+
+// A Rule resolves the tile identifier of a cell from the terrains of its
+// neighborhood.
+type Rule interface {
+	// Resolve returns the tile identifier of the cell with the provided
+	// neighborhood. The boolean return value is false if no tile matches.
+	Resolve(n Neighborhood) (id tileset.TileID, ok bool)
+}
+
+// A Resolver keeps track of the terrains painted onto the cells of a map, and
+// resolves the cells to the tile identifiers specified by the rules of each
+// terrain.
+type Resolver struct {
+	// Resolved map.
+	Map *grid.Map
+	// Mapping from terrains to the rules which resolve cells of the terrain.
+	Rules map[Terrain]Rule
+	// Terrains of the cells, indexed by column and row.
+	terrains [][]Terrain
+}
+
+// NewResolver returns a new resolver of the cells of m, all of which initially
+// have no terrain.
+func NewResolver(m *grid.Map) (r *Resolver) {
+	r = &Resolver{
+		Map:      m,
+		Rules:    make(map[Terrain]Rule),
+		terrains: make([][]Terrain, m.Cols()),
+	}
+	for col := range r.terrains {
+		r.terrains[col] = make([]Terrain, m.Rows())
+	}
+	return r
+}
+
+// Terrain returns the terrain of the cell at loc, wrapped around the wrapping
+// axes of the map. The boolean return value is false if loc is outside of the
+// map.
+func (r *Resolver) Terrain(loc grid.Location) (t Terrain, ok bool) {
+	loc, ok = r.Map.Normalize(loc)
+	if !ok {
+		return 0, false
+	}
+	return r.terrains[loc.Col][loc.Row], true
+}
+
+// Set paints the terrain t onto the cell at loc, wrapped around the wrapping
+// axes of the map, and incrementally resolves the cell and its neighbours. It
+// is an error for loc to be outside of the map.
+func (r *Resolver) Set(loc grid.Location, t Terrain) error {
+	norm, ok := r.Map.Normalize(loc)
+	if !ok {
+		return fmt.Errorf("autotile: location (%d, %d) outside of %dx%d map", loc.Col, loc.Row, r.Map.Cols(), r.Map.Rows())
+	}
+	loc = norm
+	r.terrains[loc.Col][loc.Row] = t
+	r.resolve(loc)
+	for _, delta := range deltas {
+		if neighbor, ok := r.Map.Offset(loc, delta.Col, delta.Row); ok {
+			r.resolve(neighbor)
+		}
+	}
+	return nil
+}
+
+// ResolveAll resolves every cell of the map, as required after changing the
+// rules.
+func (r *Resolver) ResolveAll() {
+	for col := range r.terrains {
+		for row := range r.terrains[col] {
+			r.resolve(grid.Loc(col, row))
+		}
+	}
+}
+
+// Neighborhood returns the neighborhood of the cell at loc. The cell and its
+// neighbours wrap around the wrapping axes of the map; neighbours outside of
+// the map have the terrain of the cell at loc. Cells outside of the map have no
+// terrain.
+func (r *Resolver) Neighborhood(loc grid.Location) (n Neighborhood) {
+	loc, ok := r.Map.Normalize(loc)
+	if !ok {
+		return n
+	}
+	n.Center = r.terrains[loc.Col][loc.Row]
+	for dir, delta := range deltas {
+		if neighbor, ok := r.Map.Offset(loc, delta.Col, delta.Row); ok {
+			n.Neighbors[dir] = r.terrains[neighbor.Col][neighbor.Row]
+		} else {
+			n.Neighbors[dir] = n.Center
+		}
+	}
+	return n
+}
+
+// resolve resolves the tile identifier of the cell at the normalized location
+// loc. Cells without a terrain, or without a matching tile, are cleared.
+func (r *Resolver) resolve(loc grid.Location) {
+	var id tileset.TileID
+	if rule, ok := r.Rules[r.terrains[loc.Col][loc.Row]]; ok {
+		id, _ = rule.Resolve(r.Neighborhood(loc))
+	}
+	r.Map.Cells[loc.Col][loc.Row] = id
+}
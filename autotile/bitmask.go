@@ -0,0 +1,75 @@
+package autotile
+
+import (
+	"github.com/mewmew/pgg/tileset"
+)
+
+// A Bitmask4 rule resolves cells based on which of their four edge neighbours
+// share their terrain. The bits of the mask are set for matching neighbours as
+// follows: north = 1, east = 2, south = 4 and west = 8.
+type Bitmask4 struct {
+	// Tile identifiers indexed by 4-bit mask.
+	Tiles [16]tileset.TileID
+}
+
+// Resolve returns the tile identifier of the cell with the provided
+// neighborhood.
+func (rule *Bitmask4) Resolve(n Neighborhood) (id tileset.TileID, ok bool) {
+	var mask uint8
+	for i, dir := range []Direction{North, East, South, West} {
+		if n.Neighbors[dir] == n.Center {
+			mask |= 1 << uint(i)
+		}
+	}
+	id = rule.Tiles[mask]
+	return id, id.IsValid()
+}
+
+// A Bitmask8 rule resolves cells based on which of their eight neighbours share
+// their terrain, as used by 47 tile blob tile sets. The bits of the mask are set
+// clockwise for matching neighbours as follows: north = 1, north east = 2,
+// east = 4, south east = 8, south = 16, south west = 32, west = 64 and north
+// west = 128. Corner bits are only set if both adjacent edge bits are set,
+// which reduces the 256 possible masks to 47 distinct masks.
+type Bitmask8 struct {
+	// Tile identifiers indexed by reduced 8-bit mask.
+	Tiles map[uint8]tileset.TileID
+}
+
+// Resolve returns the tile identifier of the cell with the provided
+// neighborhood.
+func (rule *Bitmask8) Resolve(n Neighborhood) (id tileset.TileID, ok bool) {
+	var mask uint8
+	for dir := North; dir <= NorthWest; dir++ {
+		if n.Neighbors[dir] == n.Center {
+			mask |= 1 << uint(dir)
+		}
+	}
+	id, ok = rule.Tiles[Blob(mask)]
+	return id, ok && id.IsValid()
+}
+
+// Blob reduces the 8-bit mask by clearing the corner bits of which at least
+// one adjacent edge bit is not set.
+func Blob(mask uint8) uint8 {
+	for dir := NorthEast; dir <= NorthWest; dir += 2 {
+		prev := dir - 1
+		next := (dir + 1) % 8
+		if mask&(1<<uint(prev)) == 0 || mask&(1<<uint(next)) == 0 {
+			mask &^= 1 << uint(dir)
+		}
+	}
+	return mask
+}
+
+// BlobMasks returns the 47 distinct reduced 8-bit masks of blob tile sets, in
+// ascending order.
+func BlobMasks() []uint8 {
+	var masks []uint8
+	for mask := 0; mask < 256; mask++ {
+		if Blob(uint8(mask)) == uint8(mask) {
+			masks = append(masks, uint8(mask))
+		}
+	}
+	return masks
+}
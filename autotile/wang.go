@@ -0,0 +1,78 @@
+package autotile
+
+import (
+	"github.com/mewmew/pgg/tileset"
+)
+
+// A WangSet rule resolves cells of a given terrain to the Wang tile whose
+// colors best match the terrains surrounding the cell, in the style of the
+// Wang sets of the Tiled map editor. Unlike bitmask rules, Wang sets support
+// transitions between any number of terrains.
+//
+// The color of an edge is the terrain of the neighbour across the edge. The
+// color of a corner is the terrain of the first adjacent edge neighbour, in
+// clockwise order, that differs from the terrain of the cell; or the terrain
+// of the diagonal neighbour if both adjacent edge neighbours share the terrain
+// of the cell.
+type WangSet struct {
+	// Wang tiles of the set.
+	Tiles []WangTile
+}
+
+// A WangTile is a tile of a Wang set.
+type WangTile struct {
+	// Tile identifier.
+	ID tileset.TileID
+	// Colors of the edges and corners of the tile, indexed by direction. The
+	// zero value matches any terrain.
+	Colors [8]Terrain
+}
+
+// Resolve returns the tile identifier of the cell with the provided
+// neighborhood. Of the tiles without mismatching colors, the tile with the most
+// matching colors is selected; ties are resolved in favour of the tile first in
+// the set.
+func (set *WangSet) Resolve(n Neighborhood) (id tileset.TileID, ok bool) {
+	colors := wangColors(n)
+	best := -1
+	for _, tile := range set.Tiles {
+		score := 0
+		for dir, c := range tile.Colors {
+			if c == 0 {
+				continue
+			}
+			if c != colors[dir] {
+				score = -1
+				break
+			}
+			score++
+		}
+		if score > best {
+			best = score
+			id = tile.ID
+		}
+	}
+	return id, best >= 0
+}
+
+// wangColors returns the edge and corner colors of the cell with the provided
+// neighborhood.
+func wangColors(n Neighborhood) (colors [8]Terrain) {
+	for dir := North; dir <= NorthWest; dir++ {
+		if !dir.IsCorner() {
+			colors[dir] = n.Neighbors[dir]
+			continue
+		}
+		prev := dir - 1
+		next := (dir + 1) % 8
+		switch {
+		case n.Neighbors[prev] != n.Center:
+			colors[dir] = n.Neighbors[prev]
+		case n.Neighbors[next] != n.Center:
+			colors[dir] = n.Neighbors[next]
+		default:
+			colors[dir] = n.Neighbors[dir]
+		}
+	}
+	return colors
+}
@@ -0,0 +1,310 @@
+// Package camera moves views to track targets in the game world.
+package camera
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/mewmew/pgg/grid"
+	"github.com/mewmew/pgg/view"
+)
+
+// A Smoothing specifies how a camera moves towards its goal.
+type Smoothing int
+
+// Smoothings.
+const (
+	// Snap moves the camera to its goal immediately.
+	Snap Smoothing = iota
+	// Lerp moves the camera a fixed fraction of the remaining distance
+	// towards its goal per unit of time; it starts fast and slows down.
+	Lerp
+	// Damped moves the camera as a critically damped spring attached to its
+	// goal; it accelerates and decelerates smoothly without overshooting.
+	Damped
+)
+
+// A Camera moves a view to track a target in the game world. The position of
+// the camera is the world pixel at the center of the view.
+type Camera struct {
+	// View moved by the camera.
+	*view.View
+	// Smoothing of the camera movement towards its goal.
+	Smoothing Smoothing
+	// Approximate time for the camera to catch up with its goal; used by the
+	// Lerp and Damped smoothings.
+	SmoothTime time.Duration
+	// Deadzone relative to the center of the view, within which the target may
+	// move without moving the camera. The zero value follows the target
+	// exactly.
+	Deadzone image.Rectangle
+	// Duration of target movement to look ahead by, in the direction of
+	// movement of the target.
+	LookAhead time.Duration
+	// Camera position in world pixels.
+	x, y float64
+	// Center of the view last applied by the camera, used to detect changes to
+	// the view made outside of the camera, such as zooming.
+	last image.Point
+	// Camera velocity in world pixels per second; used by the Damped
+	// smoothing.
+	vx, vy float64
+	// Target position in world pixels, and whether a target is followed.
+	target    image.Point
+	hasTarget bool
+	// Target position at the previous update, used to estimate the target
+	// velocity for look-ahead.
+	prev image.Point
+	// Screen shake amplitude in pixels, duration and elapsed time.
+	shakeAmp     float64
+	shakeDur     time.Duration
+	shakeElapsed time.Duration
+	shakeRand    *rand.Rand
+	// Pan tween start and end positions, duration and elapsed time.
+	panFrom, panTo [2]float64
+	panDur         time.Duration
+	panElapsed     time.Duration
+	panning        bool
+}
+
+// New returns a new camera which moves the provided view. The camera is
+// initially positioned at the center of the view.
+func New(v *view.View) (c *Camera) {
+	c = &Camera{
+		View:      v,
+		shakeRand: rand.New(rand.NewSource(1)),
+	}
+	center := c.center()
+	c.x, c.y = float64(center.X), float64(center.Y)
+	c.last = center
+	return c
+}
+
+// Position returns the position of the camera, the world pixel at the center of
+// the view.
+func (c *Camera) Position() image.Point {
+	return image.Pt(round(c.x), round(c.y))
+}
+
+// CenterOn moves the camera to center on the world pixel p immediately,
+// cancelling any ongoing pan.
+func (c *Camera) CenterOn(p image.Point) {
+	c.x, c.y = float64(p.X), float64(p.Y)
+	c.vx, c.vy = 0, 0
+	c.panning = false
+	c.apply()
+}
+
+// Follow sets the target followed by the camera to the world pixel p. Follow is
+// typically called once per frame with the position of the player.
+func (c *Camera) Follow(p image.Point) {
+	if !c.hasTarget {
+		c.prev = p
+	}
+	c.target = p
+	c.hasTarget = true
+}
+
+// Unfollow stops the camera from following its target.
+func (c *Camera) Unfollow() {
+	c.hasTarget = false
+	c.vx, c.vy = 0, 0
+}
+
+// PanTo moves the camera to center on the world pixel p over the duration d,
+// easing in and out. Following the target is suspended while panning. On
+// wrapping worlds, the camera pans the shortest way around.
+func (c *Camera) PanTo(p image.Point, d time.Duration) {
+	if d <= 0 {
+		c.CenterOn(p)
+		return
+	}
+	c.sync()
+	delta := c.delta(c.Position(), p)
+	c.panFrom = [2]float64{c.x, c.y}
+	c.panTo = [2]float64{c.x + float64(delta.X), c.y + float64(delta.Y)}
+	c.panDur = d
+	c.panElapsed = 0
+	c.panning = true
+}
+
+// Panning returns true if the camera is panning.
+func (c *Camera) Panning() bool {
+	return c.panning
+}
+
+// Shake shakes the view by up to amplitude pixels in each direction, decaying
+// linearly over the duration d.
+func (c *Camera) Shake(amplitude float64, d time.Duration) {
+	c.shakeAmp = amplitude
+	c.shakeDur = d
+	c.shakeElapsed = 0
+}
+
+// ZoomAt sets the zoom factor of the view, keeping the world pixel at the
+// screen point p in place, and moves the camera along with the view.
+func (c *Camera) ZoomAt(zoom float64, p image.Point) {
+	c.View.ZoomAt(zoom, p)
+	c.sync()
+}
+
+// SetZoom sets the zoom factor of the view, keeping the world pixel at the
+// center of the view in place, and moves the camera along with the view.
+func (c *Camera) SetZoom(zoom float64) {
+	c.View.SetZoom(zoom)
+	c.sync()
+}
+
+// Update advances the camera by dt and moves its view accordingly. Changes to
+// the view made outside of the camera since the last update, such as zooming
+// the view directly, move the camera along with the view.
+func (c *Camera) Update(dt time.Duration) {
+	c.sync()
+	secs := dt.Seconds()
+	switch {
+	case c.panning:
+		c.panElapsed += dt
+		t := 1.0
+		if c.panElapsed < c.panDur {
+			t = float64(c.panElapsed) / float64(c.panDur)
+		}
+		// Smoothstep easing.
+		t = t * t * (3 - 2*t)
+		c.x = c.panFrom[0] + (c.panTo[0]-c.panFrom[0])*t
+		c.y = c.panFrom[1] + (c.panTo[1]-c.panFrom[1])*t
+		if c.panElapsed >= c.panDur {
+			c.panning = false
+		}
+	case c.hasTarget:
+		gx, gy := c.goal(secs)
+		c.x, c.vx = c.smooth(c.x, gx, c.vx, secs)
+		c.y, c.vy = c.smooth(c.y, gy, c.vy, secs)
+	}
+	c.prev = c.target
+	if c.shakeElapsed < c.shakeDur {
+		c.shakeElapsed += dt
+	}
+	c.apply()
+}
+
+// sync moves the camera by the displacement of the center of the view since the
+// camera last moved it, so that changes to the view made outside of the camera
+// are kept.
+func (c *Camera) sync() {
+	center := c.center()
+	if center == c.last {
+		return
+	}
+	d := c.delta(c.last, center)
+	c.x += float64(d.X)
+	c.y += float64(d.Y)
+	c.last = center
+}
+
+// goal returns the position the camera moves towards, which keeps the target
+// within the deadzone and looks ahead in the direction of movement of the
+// target. On wrapping worlds, the camera moves towards the nearest copy of the
+// target.
+func (c *Camera) goal(secs float64) (gx, gy float64) {
+	d := c.delta(c.Position(), c.target)
+	tx := c.x + float64(d.X)
+	ty := c.y + float64(d.Y)
+	if c.LookAhead > 0 && secs > 0 {
+		ahead := c.LookAhead.Seconds() / secs
+		v := c.delta(c.prev, c.target)
+		tx += float64(v.X) * ahead
+		ty += float64(v.Y) * ahead
+	}
+	gx = follow(c.x, tx, float64(c.Deadzone.Min.X), float64(c.Deadzone.Max.X))
+	gy = follow(c.y, ty, float64(c.Deadzone.Min.Y), float64(c.Deadzone.Max.Y))
+	return gx, gy
+}
+
+// delta returns the shortest displacement from the world pixel a to b, wrapping
+// around the wrapping axes of the world.
+func (c *Camera) delta(a, b image.Point) image.Point {
+	// World pixels wrap around the wrapping axes like the columns and rows of
+	// a map with a cell per pixel.
+	world := c.World().Size()
+	dx, dy := c.Wrap.Delta(grid.Loc(a.X, a.Y), grid.Loc(b.X, b.Y), world.X, world.Y)
+	return image.Pt(dx, dy)
+}
+
+// follow returns the camera coordinate closest to pos which keeps the target
+// coordinate t within the deadzone [min, max] relative to the camera.
+func follow(pos, t, min, max float64) float64 {
+	switch {
+	case t < pos+min:
+		return t - min
+	case t > pos+max:
+		return t - max
+	}
+	return pos
+}
+
+// smooth moves the camera coordinate pos towards the goal coordinate over secs
+// seconds, as specified by the smoothing of the camera. The velocity vel is
+// used and updated by the Damped smoothing.
+func (c *Camera) smooth(pos, goal, vel, secs float64) (float64, float64) {
+	smoothTime := c.SmoothTime.Seconds()
+	if smoothTime <= 0 {
+		return goal, 0
+	}
+	switch c.Smoothing {
+	case Lerp:
+		return pos + (goal-pos)*(1-math.Exp(-secs/smoothTime)), 0
+	case Damped:
+		// Critically damped spring; see Game Programming Gems 4, chapter 1.10.
+		omega := 2 / smoothTime
+		x := omega * secs
+		exp := 1 / (1 + x + 0.48*x*x + 0.235*x*x*x)
+		change := pos - goal
+		temp := (vel + omega*change) * secs
+		vel = (vel - omega*temp) * exp
+		return goal + (change+temp)*exp, vel
+	}
+	return goal, 0
+}
+
+// apply moves the view to center on the camera position, offset by the screen
+// shake. The camera position is kept within the range of the view.
+func (c *Camera) apply() {
+	half := c.Size().Div(2)
+	want := c.Position().Sub(half)
+	c.MoveTo(want)
+	// Keep the camera from drifting past the edges of the world, and follow
+	// the view around wrapping axes.
+	if got := c.Offset(); got != want {
+		if got.X != want.X {
+			c.x += float64(got.X - want.X)
+			if c.Wrap&grid.WrapHorizontal == 0 {
+				c.vx = 0
+			}
+		}
+		if got.Y != want.Y {
+			c.y += float64(got.Y - want.Y)
+			if c.Wrap&grid.WrapVertical == 0 {
+				c.vy = 0
+			}
+		}
+	}
+	if c.shakeElapsed < c.shakeDur {
+		amp := c.shakeAmp * (1 - float64(c.shakeElapsed)/float64(c.shakeDur))
+		dx := round((c.shakeRand.Float64()*2 - 1) * amp)
+		dy := round((c.shakeRand.Float64()*2 - 1) * amp)
+		c.Move(image.Pt(dx, dy))
+	}
+	c.last = c.center()
+}
+
+// center returns the world pixel at the center of the view.
+func (c *Camera) center() image.Point {
+	return c.Offset().Add(c.Size().Div(2))
+}
+
+// round returns x rounded to the nearest integer.
+func round(x float64) int {
+	return int(math.Floor(x + 0.5))
+}
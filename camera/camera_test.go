@@ -0,0 +1,49 @@
+package camera
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/mewmew/pgg/grid"
+	"github.com/mewmew/pgg/view"
+)
+
+func TestZoomAt(t *testing.T) {
+	golden := []struct {
+		name string
+		// Zooms the view of the camera at the screen point p.
+		zoom func(c *Camera, p image.Point)
+		// Target followed by the camera, if any, relative to its position.
+		target *image.Point
+		wrap   grid.Wrap
+	}{
+		{name: "camera zoom in", zoom: func(c *Camera, p image.Point) { c.ZoomAt(2, p) }},
+		{name: "camera zoom out", zoom: func(c *Camera, p image.Point) { c.ZoomAt(0.8, p) }},
+		{name: "view zoom", zoom: func(c *Camera, p image.Point) { c.View.ZoomAt(2, p) }},
+		{name: "follow within deadzone", zoom: func(c *Camera, p image.Point) { c.ZoomAt(2, p) }, target: &image.Point{X: 40, Y: 0}},
+		{name: "wrapping world", zoom: func(c *Camera, p image.Point) { c.ZoomAt(2, p) }, wrap: grid.WrapHorizontal},
+	}
+	geom := grid.Geometry{CellWidth: 32, CellHeight: 32}
+	for _, g := range golden {
+		v := view.NewView(192, 192, geom.Size(20, 20), geom)
+		v.Wrap = g.wrap
+		c := New(v)
+		c.Smoothing = Damped
+		c.SmoothTime = 300 * time.Millisecond
+		c.Deadzone = image.Rect(-100, -100, 100, 100)
+		c.CenterOn(image.Pt(20, 320))
+		if g.target != nil {
+			c.Follow(c.Position().Add(*g.target))
+		}
+		cursor := image.Pt(40, 150)
+		g.zoom(c, cursor)
+		want := v.ScreenToWorld(cursor)
+		for i := 0; i < 10; i++ {
+			c.Update(time.Second / 60)
+		}
+		if got := v.ScreenToWorld(cursor); got != want {
+			t.Errorf("%s: world pixel under cursor mismatch; expected %v, got %v", g.name, want, got)
+		}
+	}
+}
@@ -11,16 +11,31 @@ Flags:
 		Tile width.
 	-h (default=32)
 		Tile height.
+	-margin (default=0)
+		Margin around the tiles of the sprite sheet.
+	-spacing (default=0)
+		Spacing between adjacent tiles.
+	-x (default=0)
+		Horizontal origin offset of the tiles within the sprite sheet.
+	-y (default=0)
+		Vertical origin offset of the tiles within the sprite sheet.
+	-skipempty (default=false)
+		Skip fully transparent tiles.
+	-dedup (default=false)
+		Skip tiles identical to previously extracted tiles.
 
 Examples:
 
 	tiledump -w 64 -h 64 tileset.png
+	tiledump -w 16 -h 16 -margin 1 -spacing 2 tileset.png
+	tiledump -skipempty -dedup tileset.png
 */
 package main
 
 import (
 	"flag"
 	"fmt"
+	"image"
 	"log"
 	"os"
 
@@ -32,9 +47,25 @@ import (
 // The tile width and height are specifiable from command line.
 var tileWidth, tileHeight int
 
+// The layout of the tiles within the sprite sheet is specifiable from command
+// line.
+var (
+	margin, spacing int
+	offX, offY      int
+)
+
+// Specifies whether to skip empty and duplicate tiles.
+var skipEmpty, dedup bool
+
 func init() {
 	flag.IntVar(&tileWidth, "w", 32, "Tile width.")
 	flag.IntVar(&tileHeight, "h", 32, "Tile height.")
+	flag.IntVar(&margin, "margin", 0, "Margin around the tiles of the sprite sheet.")
+	flag.IntVar(&spacing, "spacing", 0, "Spacing between adjacent tiles.")
+	flag.IntVar(&offX, "x", 0, "Horizontal origin offset of the tiles within the sprite sheet.")
+	flag.IntVar(&offY, "y", 0, "Vertical origin offset of the tiles within the sprite sheet.")
+	flag.BoolVar(&skipEmpty, "skipempty", false, "Skip fully transparent tiles.")
+	flag.BoolVar(&dedup, "dedup", false, "Skip tiles identical to previously extracted tiles.")
 	flag.Usage = usage
 }
 
@@ -46,6 +77,8 @@ func usage() {
 	fmt.Fprintln(os.Stderr)
 	fmt.Fprintln(os.Stderr, "Examples:")
 	fmt.Fprintln(os.Stderr, "  tiledump -w 64 -h 64 tileset.png")
+	fmt.Fprintln(os.Stderr, "  tiledump -w 16 -h 16 -margin 1 -spacing 2 tileset.png")
+	fmt.Fprintln(os.Stderr, "  tiledump -skipempty -dedup tileset.png")
 }
 
 func main() {
@@ -60,7 +93,8 @@ func main() {
 
 // tiledump extracts the tile images contained within the provided tile set.
 func tiledump(imgPath string) (err error) {
-	ts, err := tileset.Open(imgPath, tileWidth, tileHeight)
+	off := image.Pt(offX, offY)
+	ts, err := tileset.Open(imgPath, tileWidth, tileHeight, tileset.Margin(margin), tileset.Spacing(spacing), tileset.Offset(off))
 	if err != nil {
 		return err
 	}
@@ -69,7 +103,17 @@ func tiledump(imgPath string) (err error) {
 	if err != nil {
 		return err
 	}
-	for id := tileset.TileID(1); id <= ts.LastID(); id++ {
+	last := ts.LastID()
+	if skipEmpty {
+		last = ts.LastUsedID()
+	}
+	for id := tileset.TileID(1); id <= last; id++ {
+		if skipEmpty && ts.IsEmpty(id) {
+			continue
+		}
+		if dedup && ts.IsDuplicate(id) {
+			continue
+		}
 		tile := ts.Tile(id)
 		tilePath := fmt.Sprintf("%s/tile_%04d.png", tileDir, id)
 		err = imgutil.WriteFile(tilePath, tile)
@@ -1,36 +1,49 @@
 // world is a tool which initializes and renders a simple game world.
+//
+// Usage:
+//
+//	world [-level FILE]
+//
+// The level is read from the provided text map, as described by the grid
+// package, and defaults to level.txt.
 package main
 
 import (
+	"flag"
 	"image"
-	"image/draw"
 	"log"
 
 	"github.com/mewkiz/pkg/imgutil"
+	"github.com/mewmew/pgg/fog"
+	"github.com/mewmew/pgg/fov"
 	"github.com/mewmew/pgg/grid"
+	"github.com/mewmew/pgg/renderer"
 	"github.com/mewmew/pgg/tileset"
 	"github.com/mewmew/pgg/view"
 )
 
-func init() {
-	// Specify the width and height of grid cells.
-	grid.CellWidth = 48
-	grid.CellHeight = 48
-}
+// levelPath specifies the text map of the level.
+var levelPath string
 
 func main() {
+	flag.StringVar(&levelPath, "level", "level.txt", "Text map of the level.")
+	flag.Parse()
 	err := world()
 	if err != nil {
 		log.Fatalln(err)
 	}
 }
 
-// Number of columns and rows of the entire map.
+// Width and height of grid cells.
 const (
-	MapCols = 9
-	MapRows = 11
+	CellWidth  = 48
+	CellHeight = 48
 )
 
+// sightRadius specifies how far the viewer at the center of the view can see,
+// in number of cells.
+const sightRadius = 3
+
 // Tile identifiers.
 const (
 	Grass  tileset.TileID = 1
@@ -42,49 +55,51 @@ const (
 // world initializes and renders the game world.
 func world() (err error) {
 	// Initialize map.
-	m := grid.NewMap(MapCols, MapRows)
-	initLevel(m)
+	geom := grid.Geometry{
+		CellWidth:  CellWidth,
+		CellHeight: CellHeight,
+	}
+	level, err := grid.OpenText(levelPath, geom, tileNames)
+	if err != nil {
+		return err
+	}
+	m := grid.NewLayeredMap(level.Cols(), level.Rows(), geom)
+	ground := m.AddLayer("ground")
+	ground.Cells = level.Cells
+	m.AddLayer("overhead")
 
 	// Initialize view.
 	viewCols := 6
 	viewRows := 6
-	width := viewCols * grid.CellWidth
-	height := viewRows * grid.CellHeight
-	mapWidth := MapCols * grid.CellWidth
-	mapHeight := MapRows * grid.CellHeight
-	end := image.Pt(mapWidth, mapHeight)
-	v := view.NewView(width, height, end)
+	size := geom.Size(viewCols, viewRows)
+	width := size.X
+	height := size.Y
+	end := m.Bounds().Max
+	v := view.NewView(width, height, end, geom)
 
 	// Initialize world image.
 	world := image.NewRGBA(image.Rect(0, 0, width, height))
 
 	// Initialize tileset.
-	tileWidth := grid.CellWidth
-	tileHeight := grid.CellHeight
+	tileWidth := geom.CellWidth
+	tileHeight := geom.CellHeight
 	ts, err := tileset.Open("tileset 2.png", tileWidth, tileHeight)
 	if err != nil {
 		return err
 	}
+	tilesets := tileset.NewRegistry()
+	tilesets.Add(ts)
 
-	// drawTile draws the tile at the specified column and row.
-	drawTile := func(col, row int) {
-		x := col*grid.CellWidth - v.X()
-		y := row*grid.CellHeight - v.Y()
-		dr := image.Rect(x, y, x+tileWidth, y+tileHeight)
-		viewCol := col + v.Col()
-		viewRow := row + v.Row()
-		id := tileset.TileID(m[viewCol][viewRow])
-		tile := ts.Tile(id)
-		sp := tile.Bounds().Min
-		draw.Draw(world, dr, tile, sp, draw.Over)
-	}
+	// Initialize fog of war, as seen from the center of the view.
+	fm := fog.New(m.Cols(), m.Rows())
+	viewer := fov.NewViewer(ground.Map, fov.Opaque())
+	center := grid.Loc(v.Col()+viewCols/2, v.Row()+viewRows/2)
+	fm.Update(viewer.Visible(center, sightRadius))
 
 	// Draw loop.
-	for col := 0; col < v.Cols(); col++ {
-		for row := 0; row < v.Rows(); row++ {
-			drawTile(col, row)
-		}
-	}
+	r := renderer.NewImage(world, tilesets)
+	renderer.DrawLayers(r, m, v)
+	renderer.DrawFog(r, fm, v)
 
 	// Output world image.
 	err = imgutil.WriteFile("world.png", world)
@@ -95,122 +110,10 @@ func world() (err error) {
 	return nil
 }
 
-// initLevel initializes the provided map with the tiles of a simple level.
-func initLevel(m grid.Map) {
-	// Col 0.
-	m[0][0] = grid.Cell(Water)
-	m[0][1] = grid.Cell(Water)
-	m[0][2] = grid.Cell(Water)
-	m[0][3] = grid.Cell(Water)
-	m[0][4] = grid.Cell(Water)
-	m[0][5] = grid.Cell(Water)
-	m[0][6] = grid.Cell(Water)
-	m[0][7] = grid.Cell(Water)
-	m[0][8] = grid.Cell(Water)
-	m[0][9] = grid.Cell(Water)
-	m[0][10] = grid.Cell(Water)
-
-	// Col 1.
-	m[1][0] = grid.Cell(Water)
-	m[1][1] = grid.Cell(Water)
-	m[1][2] = grid.Cell(Water)
-	m[1][3] = grid.Cell(Water)
-	m[1][4] = grid.Cell(Water)
-	m[1][5] = grid.Cell(Water)
-	m[1][6] = grid.Cell(Water)
-	m[1][7] = grid.Cell(Water)
-	m[1][8] = grid.Cell(Water)
-	m[1][9] = grid.Cell(Water)
-	m[1][10] = grid.Cell(Water)
-
-	// Col 2.
-	m[2][0] = grid.Cell(Water)
-	m[2][1] = grid.Cell(Water)
-	m[2][2] = grid.Cell(Sand)
-	m[2][3] = grid.Cell(Sand)
-	m[2][4] = grid.Cell(Sand)
-	m[2][5] = grid.Cell(Sand)
-	m[2][6] = grid.Cell(Sand)
-	m[2][7] = grid.Cell(Water)
-	m[2][8] = grid.Cell(Water)
-	m[2][9] = grid.Cell(Water)
-	m[2][10] = grid.Cell(Water)
-
-	// Col 3.
-	m[3][0] = grid.Cell(Sand)
-	m[3][1] = grid.Cell(Sand)
-	m[3][2] = grid.Cell(Gravel)
-	m[3][3] = grid.Cell(Gravel)
-	m[3][4] = grid.Cell(Gravel)
-	m[3][5] = grid.Cell(Gravel)
-	m[3][6] = grid.Cell(Sand)
-	m[3][7] = grid.Cell(Sand)
-	m[3][8] = grid.Cell(Sand)
-	m[3][9] = grid.Cell(Water)
-	m[3][10] = grid.Cell(Water)
-
-	// Col 4.
-	m[4][0] = grid.Cell(Sand)
-	m[4][1] = grid.Cell(Gravel)
-	m[4][2] = grid.Cell(Gravel)
-	m[4][3] = grid.Cell(Gravel)
-	m[4][4] = grid.Cell(Gravel)
-	m[4][5] = grid.Cell(Gravel)
-	m[4][6] = grid.Cell(Gravel)
-	m[4][7] = grid.Cell(Gravel)
-	m[4][8] = grid.Cell(Sand)
-	m[4][9] = grid.Cell(Sand)
-	m[4][10] = grid.Cell(Water)
-
-	// Col 5.
-	m[5][0] = grid.Cell(Gravel)
-	m[5][1] = grid.Cell(Gravel)
-	m[5][2] = grid.Cell(Gravel)
-	m[5][3] = grid.Cell(Grass)
-	m[5][4] = grid.Cell(Grass)
-	m[5][5] = grid.Cell(Grass)
-	m[5][6] = grid.Cell(Gravel)
-	m[5][7] = grid.Cell(Gravel)
-	m[5][8] = grid.Cell(Gravel)
-	m[5][9] = grid.Cell(Sand)
-	m[5][10] = grid.Cell(Water)
-
-	// Col 6.
-	m[6][0] = grid.Cell(Gravel)
-	m[6][1] = grid.Cell(Gravel)
-	m[6][2] = grid.Cell(Gravel)
-	m[6][3] = grid.Cell(Grass)
-	m[6][4] = grid.Cell(Grass)
-	m[6][5] = grid.Cell(Grass)
-	m[6][6] = grid.Cell(Gravel)
-	m[6][7] = grid.Cell(Gravel)
-	m[6][8] = grid.Cell(Gravel)
-	m[6][9] = grid.Cell(Sand)
-	m[6][10] = grid.Cell(Water)
-
-	// Col 7.
-	m[7][0] = grid.Cell(Gravel)
-	m[7][1] = grid.Cell(Gravel)
-	m[7][2] = grid.Cell(Gravel)
-	m[7][3] = grid.Cell(Grass)
-	m[7][4] = grid.Cell(Grass)
-	m[7][5] = grid.Cell(Grass)
-	m[7][6] = grid.Cell(Gravel)
-	m[7][7] = grid.Cell(Gravel)
-	m[7][8] = grid.Cell(Gravel)
-	m[7][9] = grid.Cell(Sand)
-	m[7][10] = grid.Cell(Water)
-
-	// Col 8.
-	m[8][0] = grid.Cell(Water)
-	m[8][1] = grid.Cell(Sand)
-	m[8][2] = grid.Cell(Water)
-	m[8][3] = grid.Cell(Grass)
-	m[8][4] = grid.Cell(Water)
-	m[8][5] = grid.Cell(Sand)
-	m[8][6] = grid.Cell(Water)
-	m[8][7] = grid.Cell(Grass)
-	m[8][8] = grid.Cell(Water)
-	m[8][9] = grid.Cell(Sand)
-	m[8][10] = grid.Cell(Water)
+// tileNames maps the tile names of text maps to tile identifiers.
+var tileNames = map[string]tileset.TileID{
+	"Grass":  Grass,
+	"Sand":   Sand,
+	"Water":  Water,
+	"Gravel": Gravel,
 }
@@ -0,0 +1,91 @@
+package fog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// magic identifies encoded fog of war maps.
+const magic = "fog"
+
+// version is the version of the binary encoding.
+const version = 1
+
+// MarshalBinary implements encoding.BinaryMarshaler. Cells which are currently
+// visible are encoded as explored, since visibility is recomputed after
+// loading.
+func (fm *Map) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteString(magic)
+	buf.WriteByte(version)
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], uint64(fm.Cols()))
+	buf.Write(tmp[:n])
+	n = binary.PutUvarint(tmp[:], uint64(fm.Rows()))
+	buf.Write(tmp[:n])
+	// Pack the explored state of eight cells per byte, in column-major order.
+	var b, nbits byte
+	for col := range fm.states {
+		for _, state := range fm.states[col] {
+			if state != Unexplored {
+				b |= 1 << nbits
+			}
+			nbits++
+			if nbits == 8 {
+				buf.WriteByte(b)
+				b, nbits = 0, 0
+			}
+		}
+	}
+	if nbits > 0 {
+		buf.WriteByte(b)
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. No cells are visible
+// after unmarshaling.
+func (fm *Map) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	hdr := make([]byte, len(magic)+1)
+	if _, err := r.Read(hdr); err != nil || string(hdr[:len(magic)]) != magic {
+		return errors.New("fog: invalid header")
+	}
+	if v := hdr[len(magic)]; v != version {
+		return fmt.Errorf("fog: unsupported version %d", v)
+	}
+	cols, err := binary.ReadUvarint(r)
+	if err != nil {
+		return fmt.Errorf("fog: invalid number of columns; %v", err)
+	}
+	rows, err := binary.ReadUvarint(r)
+	if err != nil {
+		return fmt.Errorf("fog: invalid number of rows; %v", err)
+	}
+	if cols == 0 || rows == 0 {
+		// Maps without cells.
+		cols, rows = 0, 0
+	}
+	if cols > math.MaxInt32 || rows > math.MaxInt32 {
+		return fmt.Errorf("fog: invalid dimensions %dx%d", cols, rows)
+	}
+	packed := data[len(data)-r.Len():]
+	if (cols*rows+7)/8 != uint64(len(packed)) {
+		return fmt.Errorf("fog: invalid data length %d for %dx%d cells", len(packed), cols, rows)
+	}
+	dec := New(int(cols), int(rows))
+	i := 0
+	for col := range dec.states {
+		for row := range dec.states[col] {
+			if packed[i/8]&(1<<uint(i%8)) != 0 {
+				dec.states[col][row] = Explored
+			}
+			i++
+		}
+	}
+	*fm = *dec
+	return nil
+}
@@ -0,0 +1,92 @@
+// Package fog keeps track of the explored and visible cells of maps, as
+// required to draw the fog of war.
+package fog
+
+import (
+	"github.com/mewmew/pgg/fov"
+	"github.com/mewmew/pgg/grid"
+)
+
+// A State specifies the fog of war state of a cell.
+type State uint8
+
+// Fog of war states.
+const (
+	// Unexplored cells have never been visible.
+	Unexplored State = iota
+	// Explored cells have been visible but are not currently visible.
+	Explored
+	// Visible cells are currently visible.
+	Visible
+)
+
+// A Map records the fog of war state of each cell of a map. It is stored
+// alongside the map and has the same dimensions.
+type Map struct {
+	// Fog of war states, indexed by column and row.
+	states [][]State
+	// Currently visible cells.
+	visible fov.Set
+}
+
+// New returns a new fog of war map of the specified number of columns and
+// rows, all cells of which are unexplored.
+func New(cols, rows int) (fm *Map) {
+	fm = &Map{
+		states: make([][]State, cols),
+	}
+	for col := range fm.states {
+		fm.states[col] = make([]State, rows)
+	}
+	return fm
+}
+
+// Cols returns the number of columns in the map.
+func (fm *Map) Cols() int {
+	return len(fm.states)
+}
+
+// Rows returns the number of rows in the map.
+func (fm *Map) Rows() int {
+	if fm.Cols() < 1 {
+		return 0
+	}
+	return len(fm.states[0])
+}
+
+// State returns the fog of war state of the cell at loc. Locations outside of
+// the map are always unexplored.
+func (fm *Map) State(loc grid.Location) State {
+	if !fm.contains(loc) {
+		return Unexplored
+	}
+	return fm.states[loc.Col][loc.Row]
+}
+
+// Update updates the fog of war with the currently visible cells, as computed
+// by a fov.Viewer. Previously visible cells which are no longer visible become
+// explored.
+func (fm *Map) Update(visible fov.Set) {
+	for loc := range fm.visible {
+		fm.states[loc.Col][loc.Row] = Explored
+	}
+	fm.visible = make(fov.Set)
+	for loc := range visible {
+		if fm.contains(loc) {
+			fm.states[loc.Col][loc.Row] = Visible
+			fm.visible[loc] = true
+		}
+	}
+}
+
+// Explore marks the cell at loc as explored, unless currently visible.
+func (fm *Map) Explore(loc grid.Location) {
+	if fm.State(loc) == Unexplored && fm.contains(loc) {
+		fm.states[loc.Col][loc.Row] = Explored
+	}
+}
+
+// contains returns true if loc is located within the map.
+func (fm *Map) contains(loc grid.Location) bool {
+	return loc.Col >= 0 && loc.Col < fm.Cols() && loc.Row >= 0 && loc.Row < fm.Rows()
+}
@@ -0,0 +1,60 @@
+// Package fov computes the field of view and line of sight between the grid
+// locations of maps.
+package fov
+
+import (
+	"github.com/mewmew/pgg/grid"
+	"github.com/mewmew/pgg/tileset"
+)
+
+// An Opacity reports whether cells of the tile specified by id block sight.
+type Opacity func(id tileset.TileID) bool
+
+// Opaque returns an opacity which reports cells of the provided tiles as
+// blocking sight, including flipped and rotated cells of the tiles.
+func Opaque(ids ...tileset.TileID) Opacity {
+	opaque := make(map[tileset.TileID]bool)
+	for _, id := range ids {
+		opaque[id.Base()] = true
+	}
+	return func(id tileset.TileID) bool {
+		return opaque[id.Base()]
+	}
+}
+
+// A Set is a set of visible grid locations.
+type Set map[grid.Location]bool
+
+// Contains returns true if loc is visible.
+func (set Set) Contains(loc grid.Location) bool {
+	return set[loc]
+}
+
+// A Viewer computes visibility on the cells of a map.
+type Viewer struct {
+	// Map of the viewer.
+	Map *grid.Map
+	// Opacity of the tiles of the map.
+	Opacity Opacity
+}
+
+// NewViewer returns a new viewer of the cells of m, which are blocked from
+// sight as specified by opacity.
+func NewViewer(m *grid.Map, opacity Opacity) (v *Viewer) {
+	v = &Viewer{
+		Map:     m,
+		Opacity: opacity,
+	}
+	return v
+}
+
+// blocks returns true if the cell at loc blocks sight. Locations are wrapped
+// around the wrapping axes of the map; locations outside of the map always
+// block sight.
+func (v *Viewer) blocks(loc grid.Location) bool {
+	loc, ok := v.Map.Normalize(loc)
+	if !ok {
+		return true
+	}
+	return v.Opacity(v.Map.Cells[loc.Col][loc.Row])
+}
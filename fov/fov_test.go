@@ -0,0 +1,26 @@
+package fov
+
+import (
+	"testing"
+
+	"github.com/mewmew/pgg/grid"
+	"github.com/mewmew/pgg/tileset"
+)
+
+func TestFlippedOpaque(t *testing.T) {
+	const wall tileset.TileID = 2
+	// A 5x1 corridor with a flipped wall tile in its middle.
+	m := grid.NewMap(5, 1, grid.DefaultGeometry())
+	m.Cells[2][0] = wall | tileset.FlipVertical | tileset.FlipDiagonal
+	v := NewViewer(m, Opaque(wall))
+	if v.LineOfSight(grid.Loc(0, 0), grid.Loc(4, 0)) {
+		t.Error("expected flipped wall to block line of sight")
+	}
+	set := v.Visible(grid.Loc(0, 0), 0)
+	if !set.Contains(grid.Loc(2, 0)) {
+		t.Error("expected flipped wall to be visible")
+	}
+	if set.Contains(grid.Loc(3, 0)) {
+		t.Error("expected cell behind flipped wall to be hidden")
+	}
+}
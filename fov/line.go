@@ -0,0 +1,32 @@
+package fov
+
+import (
+	"github.com/mewmew/pgg/grid"
+)
+
+// Line returns the grid locations of the Bresenham line from a to b, including
+// both a and b.
+func Line(a, b grid.Location) (line []grid.Location) {
+	grid.Line(a, b, func(loc grid.Location) {
+		line = append(line, loc)
+	})
+	return line
+}
+
+// LineOfSight returns true if b is visible from a along the Bresenham line
+// between them; i.e. if none of the cells strictly between a and b block
+// sight. On wrapping maps, the line follows the shortest displacement from a
+// to b.
+func (v *Viewer) LineOfSight(a, b grid.Location) bool {
+	dcol, drow := v.Map.Delta(a, b)
+	line := Line(a, a.Add(grid.Loc(dcol, drow)))
+	if len(line) <= 2 {
+		return true
+	}
+	for _, loc := range line[1 : len(line)-1] {
+		if v.blocks(loc) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,176 @@
+package fov
+
+import (
+	"github.com/mewmew/pgg/grid"
+)
+
+// Visible computes the set of cells visible from origin using symmetric
+// shadowcasting; if a cell A is visible from a cell B, then B is also visible
+// from A. Opaque cells are visible but block sight of the cells behind them.
+// Cells further than radius from origin are not visible; a radius of zero or
+// less imposes no limit. On wrapping maps, the field of view wraps around the
+// edges of the map, up to once around the map along each wrapping axis, and the
+// set holds normalized locations.
+//
+// Reference: https://www.albertford.com/shadowcasting/
+func (v *Viewer) Visible(origin grid.Location, radius int) Set {
+	set := make(Set)
+	origin, ok := v.Map.Normalize(origin)
+	if !ok {
+		return set
+	}
+	set[origin] = true
+	for q := quadrant(0); q < 4; q++ {
+		sc := &shadowcaster{v: v, set: set, origin: origin, q: q, radius: radius, depth: v.depth(q)}
+		sc.scan(row{depth: 1, start: slope{-1, 1}, end: slope{1, 1}})
+	}
+	return set
+}
+
+// depth returns the maximum depth of the rows scanned in the quadrant q; the
+// extent of the map along wrapping axes, beyond which the rows of the quadrant
+// repeat, or zero if unlimited.
+func (v *Viewer) depth(q quadrant) int {
+	switch {
+	case (q == north || q == south) && v.Map.Wrap&grid.WrapVertical != 0:
+		return v.Map.Rows()
+	case (q == east || q == west) && v.Map.Wrap&grid.WrapHorizontal != 0:
+		return v.Map.Cols()
+	}
+	return 0
+}
+
+// A quadrant is one of the four cardinal quadrants of the field of view.
+type quadrant int
+
+// Quadrants.
+const (
+	north quadrant = iota
+	east
+	south
+	west
+)
+
+// A slope is a rational number num/den with a positive denominator.
+type slope struct {
+	num, den int
+}
+
+// A row is a row of cells at a given depth from the origin of a quadrant,
+// limited by the start and end slopes of the visible sector.
+type row struct {
+	depth      int
+	start, end slope
+}
+
+// cols returns the range of columns of the row within the visible sector.
+func (r row) cols() (min, max int) {
+	// Round ties up at the start and ties down at the end of the row.
+	min = floorDiv(2*r.depth*r.start.num+r.start.den, 2*r.start.den)
+	max = ceilDiv(2*r.depth*r.end.num-r.end.den, 2*r.end.den)
+	return min, max
+}
+
+// isSymmetric returns true if the center of the cell at col of the row lies
+// within the visible sector.
+func (r row) isSymmetric(col int) bool {
+	return col*r.start.den >= r.depth*r.start.num && col*r.end.den <= r.depth*r.end.num
+}
+
+// A shadowcaster computes the visible cells of a quadrant.
+type shadowcaster struct {
+	v      *Viewer
+	set    Set
+	origin grid.Location
+	q      quadrant
+	radius int
+	// Maximum depth of scanned rows; or zero if unlimited.
+	depth int
+}
+
+// scan scans the row and recursively the rows behind it.
+func (sc *shadowcaster) scan(r row) {
+	if sc.radius > 0 && r.depth > sc.radius {
+		return
+	}
+	if sc.depth > 0 && r.depth >= sc.depth {
+		return
+	}
+	min, max := r.cols()
+	// Kind of the previous cell of the row.
+	const (
+		none = iota
+		floor
+		wall
+	)
+	prev := none
+	for col := min; col <= max; col++ {
+		loc := sc.transform(r.depth, col)
+		isWall := sc.v.blocks(loc)
+		if isWall || r.isSymmetric(col) {
+			sc.reveal(loc, r.depth, col)
+		}
+		if prev == wall && !isWall {
+			r.start = slope{num: 2*col - 1, den: 2 * r.depth}
+		}
+		if prev == floor && isWall {
+			next := row{depth: r.depth + 1, start: r.start, end: slope{num: 2*col - 1, den: 2 * r.depth}}
+			sc.scan(next)
+		}
+		if isWall {
+			prev = wall
+		} else {
+			prev = floor
+		}
+	}
+	if prev == floor {
+		sc.scan(row{depth: r.depth + 1, start: r.start, end: r.end})
+	}
+}
+
+// reveal marks the cell at loc, located at col of the row at depth, as
+// visible if within the map and the radius.
+func (sc *shadowcaster) reveal(loc grid.Location, depth, col int) {
+	loc, ok := sc.v.Map.Normalize(loc)
+	if !ok {
+		return
+	}
+	if sc.radius > 0 && depth*depth+col*col > sc.radius*sc.radius {
+		return
+	}
+	sc.set[loc] = true
+}
+
+// transform returns the grid location of the cell at col of the row at depth
+// of the quadrant.
+func (sc *shadowcaster) transform(depth, col int) grid.Location {
+	o := sc.origin
+	switch sc.q {
+	case north:
+		return grid.Loc(o.Col+col, o.Row-depth)
+	case south:
+		return grid.Loc(o.Col+col, o.Row+depth)
+	case east:
+		return grid.Loc(o.Col+depth, o.Row+col)
+	default: // west
+		return grid.Loc(o.Col-depth, o.Row+col)
+	}
+}
+
+// floorDiv returns a/b rounded towards negative infinity, for b > 0.
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
+
+// ceilDiv returns a/b rounded towards positive infinity, for b > 0.
+func ceilDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && a > 0 {
+		q++
+	}
+	return q
+}
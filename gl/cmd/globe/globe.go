@@ -1,39 +1,56 @@
 // globe is a tool which initializes and renders a simple game world using
 // OpenGL.
+//
+// Usage:
+//
+//	globe [-level FILE]
+//
+// The level is read from the provided text map, as described by the grid
+// package, and defaults to level.txt.
 package main
 
 import (
+	"flag"
 	"image"
+	"image/color"
 	"log"
 	"runtime"
 	"time"
 
 	"github.com/mewmew/glfw/win"
+	"github.com/mewmew/pgg/camera"
+	"github.com/mewmew/pgg/fog"
+	"github.com/mewmew/pgg/fov"
+	"github.com/mewmew/pgg/gl/renderer"
 	"github.com/mewmew/pgg/gl/tileset"
 	"github.com/mewmew/pgg/grid"
+	pggrenderer "github.com/mewmew/pgg/renderer"
+	pggtileset "github.com/mewmew/pgg/tileset"
 	"github.com/mewmew/pgg/view"
 	"github.com/mewmew/we"
 )
 
-func init() {
-	// Specify the width and height of grid cells.
-	grid.CellWidth = 48
-	grid.CellHeight = 48
-}
+// levelPath specifies the text map of the level.
+var levelPath string
 
 func main() {
+	flag.StringVar(&levelPath, "level", "level.txt", "Text map of the level.")
+	flag.Parse()
 	err := globe()
 	if err != nil {
 		log.Fatalln(err)
 	}
 }
 
-// Number of columns and rows of the entire map.
+// Width and height of grid cells.
 const (
-	MapCols = 9
-	MapRows = 11
+	CellWidth  = 48
+	CellHeight = 48
 )
 
+// sightRadius specifies how far the player can see, in number of cells.
+const sightRadius = 3
+
 // Tile identifiers.
 const (
 	Grass  tileset.TileID = 1
@@ -42,6 +59,9 @@ const (
 	Gravel tileset.TileID = 4
 )
 
+// highlightColor is the color blended over the selected cell.
+var highlightColor = color.NRGBA{R: 0xFF, G: 0xFF, A: 0x60}
+
 // fps corresponds to the number of frames per second that should be drawn.
 const fps = 60
 
@@ -52,18 +72,43 @@ func globe() (err error) {
 	defer runtime.UnlockOSThread()
 
 	// Initialize map.
-	m := grid.NewMap(MapCols, MapRows)
-	initLevel(m)
+	geom := grid.Geometry{
+		CellWidth:  CellWidth,
+		CellHeight: CellHeight,
+	}
+	level, err := grid.OpenText(levelPath, geom, tileNames)
+	if err != nil {
+		return err
+	}
+	m := grid.NewLayeredMap(level.Cols(), level.Rows(), geom)
+	// Wrap the globe around east to west.
+	m.Wrap = grid.WrapHorizontal
+	ground := m.AddLayer("ground")
+	ground.Cells = level.Cells
+	m.AddLayer("overhead")
 
 	// Initialize view.
 	viewCols := 6
 	viewRows := 6
-	width := viewCols * grid.CellWidth
-	height := viewRows * grid.CellHeight
-	mapWidth := MapCols * grid.CellWidth
-	mapHeight := MapRows * grid.CellHeight
-	end := image.Pt(mapWidth, mapHeight)
-	v := view.NewView(width, height, end)
+	size := geom.Size(viewCols, viewRows)
+	width := size.X
+	height := size.Y
+	end := m.Bounds().Max
+	v := view.NewView(width, height, end, geom)
+	v.MinZoom = minZoom
+	v.MaxZoom = maxZoom
+	// Center the map within the window when zoomed out past its edges.
+	v.Edge = view.Center
+	v.Wrap = m.Wrap
+
+	// Initialize the player at the center of the view, and a camera which
+	// follows the player.
+	player := image.Pt(width/2, height/2)
+	cam := camera.New(v)
+	cam.Smoothing = camera.Damped
+	cam.SmoothTime = 300 * time.Millisecond
+	cam.Deadzone = image.Rect(-CellWidth/2, -CellHeight/2, CellWidth/2, CellHeight/2)
+	cam.LookAhead = 250 * time.Millisecond
 
 	// Initialize window.
 	err = win.Open(width, height)
@@ -76,46 +121,88 @@ func globe() (err error) {
 	win.EnableCloseChan()
 	win.EnableKeyPressChan()
 	win.EnableKeyRepeatChan()
+	win.EnableMousePressChan()
+	win.EnableMouseMoveChan()
+	win.EnableScrollYChan()
 
 	// Initialize tileset.
-	tileWidth := grid.CellWidth
-	tileHeight := grid.CellHeight
+	tileWidth := geom.CellWidth
+	tileHeight := geom.CellHeight
 	ts, err := tileset.Open("tileset 2.png", tileWidth, tileHeight)
 	if err != nil {
 		return err
 	}
+	tilesets := pggtileset.NewRegistry()
+	tilesets.Add(ts)
 
-	// drawTile draws the tile at the specified column and row.
-	drawTile := func(col, row int) {
-		viewCol := col + v.Col()
-		viewRow := row + v.Row()
-		id := tileset.TileID(m[viewCol][viewRow])
-		x := col*grid.CellWidth - v.X()
-		y := row*grid.CellHeight - v.Y()
-		dp := image.Pt(x, y)
-		ts.DrawTile(id, dp)
-	}
+	// Drive animated tiles by the frame rate.
+	clock := new(pggtileset.Clock)
+	ts.Clock = clock
+
+	r := renderer.New(tilesets)
+
+	// Initialize fog of war.
+	fm := fog.New(m.Cols(), m.Rows())
+	viewer := fov.NewViewer(ground.Map, fov.Opaque())
+
+	// Screen point of the mouse cursor.
+	var cursor image.Point
+
+	// Location of the cell selected by mouse click, if any.
+	var selected grid.Location
+	hasSelected := false
 
 	c := time.Tick(time.Second / fps)
 	for {
+		// Update the fog of war, as seen by the player.
+		fm.Update(viewer.Visible(geom.Pick(player), sightRadius))
+
 		// Draw loop.
-		for col := 0; col < v.Cols(); col++ {
-			for row := 0; row < v.Rows(); row++ {
-				drawTile(col, row)
-			}
+		screen := image.Rect(0, 0, width, height)
+		if v.Viewport() != screen {
+			// Clear the letterbox borders around the map.
+			r.FillRect(screen, color.Black)
+		}
+		pggrenderer.DrawLayers(r, m, v)
+		pggrenderer.DrawFog(r, fm, v)
+		if hasSelected && v.Visible(selected) {
+			r.FillRect(v.CellRect(selected), highlightColor)
 		}
 
 		// Swap buffers to display all drawings since last screen update.
 		win.SwapBuffers()
 
+		// Advance animated tiles by one frame.
+		clock.Advance(time.Second / fps)
+
+		// Track the player.
+		cam.Follow(player)
+		cam.Update(time.Second / fps)
+
 		select {
 		case <-win.CloseChan:
 			// handle close events.
 			return nil
 		case e := <-win.KeyPressChan:
-			handleKeyPress(e, v)
+			handleKeyPress(e, &player, m.Bounds(), v)
 		case e := <-win.KeyRepeatChan:
-			handleKeyPress(we.KeyPress(e), v)
+			handleKeyPress(we.KeyPress(e), &player, m.Bounds(), v)
+		case e := <-win.MousePressChan:
+			// Select the clicked cell, or clear the selection when clicking
+			// outside of the map.
+			if e.Button == we.ButtonLeft {
+				selected = v.Pick(e.Point)
+				hasSelected = selected.Col >= 0 && selected.Col < m.Cols() && selected.Row >= 0 && selected.Row < m.Rows()
+			}
+		case e := <-win.MouseMoveChan:
+			cursor = e.Point
+		case e := <-win.ScrollYChan:
+			// Zoom around the mouse cursor.
+			if e.Off > 0 {
+				cam.ZoomAt(cam.Zoom()*zoomStep, cursor)
+			} else if e.Off < 0 {
+				cam.ZoomAt(cam.Zoom()/zoomStep, cursor)
+			}
 		case <-c:
 			// very simple implementation to update 60 times per second.
 			continue
@@ -125,137 +212,52 @@ func globe() (err error) {
 	}
 }
 
-// handleKeyPress handles key press events.
-func handleKeyPress(e we.KeyPress, v *view.View) {
+// playerSpeed specifies how far the player moves per key press, in pixels.
+const playerSpeed = 4
+
+// Zoom limits of the view, and the zoom factor applied per key press or scroll
+// step.
+const (
+	minZoom  = 0.5
+	maxZoom  = 4
+	zoomStep = 1.25
+)
+
+// handleKeyPress handles key press events, moving the player within the
+// provided world bounds, wrapping around the wrapping axes of the view, and
+// zooming the view.
+func handleKeyPress(e we.KeyPress, player *image.Point, bounds image.Rectangle, v *view.View) {
 	// handle key press events.
+	p := *player
 	switch e.Key {
 	case we.KeyUp:
-		v.Move(image.Pt(0, -2))
+		p.Y -= playerSpeed
 	case we.KeyDown:
-		v.Move(image.Pt(0, 2))
+		p.Y += playerSpeed
 	case we.KeyRight:
-		v.Move(image.Pt(2, 0))
+		p.X += playerSpeed
 	case we.KeyLeft:
-		v.Move(image.Pt(-2, 0))
+		p.X -= playerSpeed
+	case we.KeyEqual:
+		v.SetZoom(v.Zoom() * zoomStep)
+	case we.KeyMinus:
+		v.SetZoom(v.Zoom() / zoomStep)
+	}
+	if v.Wrap&grid.WrapHorizontal != 0 {
+		p.X = (p.X + bounds.Dx()) % bounds.Dx()
+	}
+	if v.Wrap&grid.WrapVertical != 0 {
+		p.Y = (p.Y + bounds.Dy()) % bounds.Dy()
+	}
+	if p.In(bounds) {
+		*player = p
 	}
 }
 
-// initLevel initializes the provided map with the tiles of a simple level.
-func initLevel(m grid.Map) {
-	// Col 0.
-	m[0][0] = grid.Cell(Water)
-	m[0][1] = grid.Cell(Water)
-	m[0][2] = grid.Cell(Water)
-	m[0][3] = grid.Cell(Water)
-	m[0][4] = grid.Cell(Water)
-	m[0][5] = grid.Cell(Water)
-	m[0][6] = grid.Cell(Water)
-	m[0][7] = grid.Cell(Water)
-	m[0][8] = grid.Cell(Water)
-	m[0][9] = grid.Cell(Water)
-	m[0][10] = grid.Cell(Water)
-
-	// Col 1.
-	m[1][0] = grid.Cell(Water)
-	m[1][1] = grid.Cell(Water)
-	m[1][2] = grid.Cell(Water)
-	m[1][3] = grid.Cell(Water)
-	m[1][4] = grid.Cell(Water)
-	m[1][5] = grid.Cell(Water)
-	m[1][6] = grid.Cell(Water)
-	m[1][7] = grid.Cell(Water)
-	m[1][8] = grid.Cell(Water)
-	m[1][9] = grid.Cell(Water)
-	m[1][10] = grid.Cell(Water)
-
-	// Col 2.
-	m[2][0] = grid.Cell(Water)
-	m[2][1] = grid.Cell(Water)
-	m[2][2] = grid.Cell(Sand)
-	m[2][3] = grid.Cell(Sand)
-	m[2][4] = grid.Cell(Sand)
-	m[2][5] = grid.Cell(Sand)
-	m[2][6] = grid.Cell(Sand)
-	m[2][7] = grid.Cell(Water)
-	m[2][8] = grid.Cell(Water)
-	m[2][9] = grid.Cell(Water)
-	m[2][10] = grid.Cell(Water)
-
-	// Col 3.
-	m[3][0] = grid.Cell(Sand)
-	m[3][1] = grid.Cell(Sand)
-	m[3][2] = grid.Cell(Gravel)
-	m[3][3] = grid.Cell(Gravel)
-	m[3][4] = grid.Cell(Gravel)
-	m[3][5] = grid.Cell(Gravel)
-	m[3][6] = grid.Cell(Sand)
-	m[3][7] = grid.Cell(Sand)
-	m[3][8] = grid.Cell(Sand)
-	m[3][9] = grid.Cell(Water)
-	m[3][10] = grid.Cell(Water)
-
-	// Col 4.
-	m[4][0] = grid.Cell(Sand)
-	m[4][1] = grid.Cell(Gravel)
-	m[4][2] = grid.Cell(Gravel)
-	m[4][3] = grid.Cell(Gravel)
-	m[4][4] = grid.Cell(Gravel)
-	m[4][5] = grid.Cell(Gravel)
-	m[4][6] = grid.Cell(Gravel)
-	m[4][7] = grid.Cell(Gravel)
-	m[4][8] = grid.Cell(Sand)
-	m[4][9] = grid.Cell(Sand)
-	m[4][10] = grid.Cell(Water)
-
-	// Col 5.
-	m[5][0] = grid.Cell(Gravel)
-	m[5][1] = grid.Cell(Gravel)
-	m[5][2] = grid.Cell(Gravel)
-	m[5][3] = grid.Cell(Grass)
-	m[5][4] = grid.Cell(Grass)
-	m[5][5] = grid.Cell(Grass)
-	m[5][6] = grid.Cell(Gravel)
-	m[5][7] = grid.Cell(Gravel)
-	m[5][8] = grid.Cell(Gravel)
-	m[5][9] = grid.Cell(Sand)
-	m[5][10] = grid.Cell(Water)
-
-	// Col 6.
-	m[6][0] = grid.Cell(Gravel)
-	m[6][1] = grid.Cell(Gravel)
-	m[6][2] = grid.Cell(Gravel)
-	m[6][3] = grid.Cell(Grass)
-	m[6][4] = grid.Cell(Grass)
-	m[6][5] = grid.Cell(Grass)
-	m[6][6] = grid.Cell(Gravel)
-	m[6][7] = grid.Cell(Gravel)
-	m[6][8] = grid.Cell(Gravel)
-	m[6][9] = grid.Cell(Sand)
-	m[6][10] = grid.Cell(Water)
-
-	// Col 7.
-	m[7][0] = grid.Cell(Gravel)
-	m[7][1] = grid.Cell(Gravel)
-	m[7][2] = grid.Cell(Gravel)
-	m[7][3] = grid.Cell(Grass)
-	m[7][4] = grid.Cell(Grass)
-	m[7][5] = grid.Cell(Grass)
-	m[7][6] = grid.Cell(Gravel)
-	m[7][7] = grid.Cell(Gravel)
-	m[7][8] = grid.Cell(Gravel)
-	m[7][9] = grid.Cell(Sand)
-	m[7][10] = grid.Cell(Water)
-
-	// Col 8.
-	m[8][0] = grid.Cell(Water)
-	m[8][1] = grid.Cell(Sand)
-	m[8][2] = grid.Cell(Water)
-	m[8][3] = grid.Cell(Grass)
-	m[8][4] = grid.Cell(Water)
-	m[8][5] = grid.Cell(Sand)
-	m[8][6] = grid.Cell(Water)
-	m[8][7] = grid.Cell(Grass)
-	m[8][8] = grid.Cell(Water)
-	m[8][9] = grid.Cell(Sand)
-	m[8][10] = grid.Cell(Water)
+// tileNames maps the tile names of text maps to tile identifiers.
+var tileNames = map[string]tileset.TileID{
+	"Grass":  Grass,
+	"Sand":   Sand,
+	"Water":  Water,
+	"Gravel": Gravel,
 }
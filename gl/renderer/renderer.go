@@ -0,0 +1,81 @@
+// Package renderer draws maps through views using OpenGL.
+package renderer
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/go-gl/gl"
+	gltileset "github.com/mewmew/pgg/gl/tileset"
+	"github.com/mewmew/pgg/tileset"
+)
+
+// A Renderer draws tile images using OpenGL. It implements the renderer
+// interface of github.com/mewmew/pgg/renderer.
+type Renderer struct {
+	// Tile sets of the drawn tile images.
+	TileSets *tileset.Registry
+	// Opacity of drawn tile images.
+	opacity float64
+	// Scale factor of drawn tile images.
+	scale float64
+}
+
+// New returns a new renderer which draws tile images from the provided tile
+// sets.
+func New(tilesets *tileset.Registry) (r *Renderer) {
+	r = &Renderer{
+		TileSets: tilesets,
+		opacity:  1,
+		scale:    1,
+	}
+	return r
+}
+
+// DrawTile draws the tile image specified by the global tile identifier id at
+// the destination point dp. Tile identifiers not contained within any OpenGL
+// tile set are ignored.
+func (r *Renderer) DrawTile(id tileset.TileID, dp image.Point) {
+	src, local, ok := r.TileSets.Lookup(id)
+	if !ok {
+		return
+	}
+	ts, ok := src.(*gltileset.TileSet)
+	if !ok {
+		return
+	}
+	ts.DrawTileScaled(local, dp, r.scale)
+}
+
+// SetOpacity sets the opacity, in the range [0, 1], of subsequently drawn tile
+// images.
+func (r *Renderer) SetOpacity(opacity float64) {
+	r.opacity = opacity
+	gltileset.SetOpacity(opacity)
+}
+
+// SetScale sets the scale factor of subsequently drawn tile images. Tile images
+// are drawn as scaled quads.
+func (r *Renderer) SetScale(scale float64) {
+	r.scale = scale
+}
+
+// FillRect blends the color c over the destination rectangle dr.
+func (r *Renderer) FillRect(dr image.Rectangle, c color.Color) {
+	// Colors are alpha-premultiplied 16-bit values.
+	cr, cg, cb, ca := c.RGBA()
+	if ca == 0 {
+		return
+	}
+	a := float32(ca) / 0xFFFF
+	gl.Disable(gl.TEXTURE_2D)
+	gl.Color4f(float32(cr)/float32(ca), float32(cg)/float32(ca), float32(cb)/float32(ca), a)
+	gl.Begin(gl.QUADS)
+	gl.Vertex2i(dr.Min.X, dr.Min.Y)
+	gl.Vertex2i(dr.Max.X, dr.Min.Y)
+	gl.Vertex2i(dr.Max.X, dr.Max.Y)
+	gl.Vertex2i(dr.Min.X, dr.Max.Y)
+	gl.End()
+	gl.Enable(gl.TEXTURE_2D)
+	gltileset.SetOpacity(r.opacity)
+}
@@ -4,7 +4,9 @@ package tileset
 import (
 	"image"
 
+	"github.com/go-gl/gl"
 	"github.com/mewmew/glfw/win"
+	pggtileset "github.com/mewmew/pgg/tileset"
 )
 
 // A TileSet is a collection of one or more tile images, all of which have the
@@ -12,61 +14,108 @@ import (
 type TileSet struct {
 	// Tile set sprite sheet.
 	img *win.Image
-	// Tile width.
-	TileWidth int
-	// Tile height.
-	TileHeight int
+	// Layout of the tile images within the sprite sheet.
+	pggtileset.Layout
+	// Animated tiles of the tile set.
+	pggtileset.Animator
+	// Tile set width and height in number of tile columns and rows
+	// respectively.
+	cols, rows int
 }
 
 // Open opens the sprite sheet specified by imgPath and returns a tile set based
-// upon it.
-func Open(imgPath string, tileWidth, tileHeight int) (ts *TileSet, err error) {
+// upon it. The tile images are arranged within the sprite sheet as specified
+// by the optional layout options.
+func Open(imgPath string, tileWidth, tileHeight int, opts ...pggtileset.Option) (ts *TileSet, err error) {
 	ts = &TileSet{
-		TileWidth:  tileWidth,
-		TileHeight: tileHeight,
+		Layout: pggtileset.NewLayout(tileWidth, tileHeight, opts...),
 	}
 	ts.img, err = win.OpenImage(imgPath)
 	if err != nil {
 		return nil, err
 	}
+	ts.cols, ts.rows = ts.Dims(ts.img.Width, ts.img.Height)
 	return ts, nil
 }
 
 // A TileID uniquely identifies a tile image in a specific tile set. The zero
-// value represents no tile image.
-type TileID int
+// value represents no tile image. It is the tile identifier type shared by the
+// tile sets of all graphics backends.
+type TileID = pggtileset.TileID
 
-// IsValid returns true if the tile identifier is valid and false if it's the
-// zero value.
-func (id TileID) IsValid() bool {
-	return id != 0
-}
-
-// tilePoint returns the top left point of the tile image in the tile set.
-func (ts *TileSet) tilePoint(id TileID) image.Point {
-	tsCols := ts.img.Width / ts.TileWidth
-	i := int(id - 1)
-	col := i % tsCols
-	row := i / tsCols
-	x := col * ts.TileWidth
-	y := row * ts.TileHeight
-	return image.Pt(x, y)
+// TileBounds returns the bounding rectangle of the tile image specified by id
+// in the sprite sheet. The flip flags of id are ignored.
+func (ts *TileSet) TileBounds(id TileID) image.Rectangle {
+	return ts.Layout.TileBounds(id, ts.cols)
 }
 
 // DrawTile draws the tile image specified by id at the provided destination
-// point dp.
+// point dp. Animated tiles draw the tile image of their current frame. The flip
+// flags of id, if any, are applied to the drawn tile image.
 func (ts *TileSet) DrawTile(id TileID, dp image.Point) {
+	ts.DrawTileScaled(id, dp, 1)
+}
+
+// DrawTileScaled draws the tile image specified by id at the provided
+// destination point dp, as a quad scaled by the provided scale factor. Animated
+// tiles draw the tile image of their current frame. The flip flags of id, if
+// any, are applied to the drawn tile image.
+func (ts *TileSet) DrawTileScaled(id TileID, dp image.Point, scale float64) {
+	if scale != 1 {
+		// Scale around the destination point.
+		gl.PushMatrix()
+		defer gl.PopMatrix()
+		x, y := float32(dp.X), float32(dp.Y)
+		gl.Translatef(x, y, 0)
+		gl.Scalef(float32(scale), float32(scale), 1)
+		gl.Translatef(-x, -y, 0)
+	}
+	id = ts.Frame(id)
 	dr := image.Rect(dp.X, dp.Y, dp.X+ts.TileWidth, dp.Y+ts.TileHeight)
-	sp := ts.tilePoint(id)
+	sp := ts.TileBounds(id).Min
+	if flags := id.Flags(); flags != 0 {
+		gl.PushMatrix()
+		defer gl.PopMatrix()
+		flip(dr, flags)
+	}
 	ts.img.DrawRect(dr, sp)
 }
 
+// flip transforms the model view matrix to apply the provided flip flags to
+// tile images drawn at the destination rectangle dr. The diagonal flip requires
+// square tiles.
+func flip(dr image.Rectangle, flags TileID) {
+	// Flip around the center of the destination rectangle; the transformations
+	// are specified in reverse order of application.
+	cx := float32(dr.Min.X+dr.Max.X) / 2
+	cy := float32(dr.Min.Y+dr.Max.Y) / 2
+	gl.Translatef(cx, cy, 0)
+	if flags&pggtileset.FlipVertical != 0 {
+		gl.Scalef(1, -1, 1)
+	}
+	if flags&pggtileset.FlipHorizontal != 0 {
+		gl.Scalef(-1, 1, 1)
+	}
+	if flags&pggtileset.FlipDiagonal != 0 {
+		// Transpose by rotating 90 degrees after mirroring vertically.
+		gl.Rotatef(90, 0, 0, 1)
+		gl.Scalef(1, -1, 1)
+	}
+	gl.Translatef(-cx, -cy, 0)
+}
+
+// SetOpacity sets the opacity, in the range [0, 1], of subsequently drawn tile
+// images.
+func SetOpacity(opacity float64) {
+	gl.Color4f(1, 1, 1, float32(opacity))
+}
+
 // LastID returns the last tile identifier contained within the tile set. An
 // empty tile set always returns the zero value.
+//
+// Every tile of the sprite sheet is counted, including trailing empty tiles,
+// so that the range of tile identifiers of the tile set remains stable.
 func (ts *TileSet) LastID() (id TileID) {
-	// TODO(u): ignore trailing empty tiles?
-	tsCols := ts.img.Width / ts.TileWidth
-	tsRows := ts.img.Height / ts.TileHeight
-	id = TileID(tsCols * tsRows)
+	id = TileID(ts.cols * ts.rows)
 	return id
 }
@@ -0,0 +1,180 @@
+package grid
+
+import (
+	"fmt"
+	"image"
+	"sort"
+)
+
+// A ChunkedMap is a sparse and unbounded map, the cells of which are stored in
+// fixed-size chunks. Chunks are created on demand when their cells are set, and
+// may be unloaded when no longer needed, for instance when far from the view.
+// Locations may be negative.
+type ChunkedMap struct {
+	// Width and height of chunks in number of columns and rows respectively;
+	// both positive.
+	ChunkCols, ChunkRows int
+	// Cell geometry of the map.
+	Geometry
+	// Generate, if non-nil, is invoked to initialize the cells of each newly
+	// created chunk; for instance to generate terrain procedurally or to load
+	// a previously unloaded chunk.
+	Generate func(chunk *Chunk)
+	// Loaded chunks, indexed by chunk location.
+	chunks map[Location]*Chunk
+}
+
+// A Chunk is a fixed-size block of cells of a chunked map.
+type Chunk struct {
+	// Location of the chunk in number of chunks; the chunk at (0, 0) contains
+	// the cell at (0, 0).
+	Loc Location
+	// Location of the top left cell of the chunk.
+	Origin Location
+	// Chunk cells, indexed by column and row relative to the origin of the
+	// chunk.
+	Cells [][]Cell
+}
+
+// NewChunkedMap returns a new chunked map without chunks, the chunks of which
+// have the specified number of columns and rows and the cells of which have the
+// provided geometry. It is an error for the chunk size not to be positive.
+func NewChunkedMap(chunkCols, chunkRows int, geom Geometry) (cm *ChunkedMap, err error) {
+	if chunkCols <= 0 || chunkRows <= 0 {
+		return nil, fmt.Errorf("grid: invalid chunk size %dx%d", chunkCols, chunkRows)
+	}
+	cm = &ChunkedMap{
+		ChunkCols: chunkCols,
+		ChunkRows: chunkRows,
+		Geometry:  geom,
+		chunks:    make(map[Location]*Chunk),
+	}
+	return cm, nil
+}
+
+// ChunkLoc returns the location of the chunk containing the cell at loc.
+func (cm *ChunkedMap) ChunkLoc(loc Location) Location {
+	return Loc(floorDiv(loc.Col, cm.ChunkCols), floorDiv(loc.Row, cm.ChunkRows))
+}
+
+// Chunk returns the loaded chunk at the chunk location cloc, or nil if not
+// loaded.
+func (cm *ChunkedMap) Chunk(cloc Location) *Chunk {
+	return cm.chunks[cloc]
+}
+
+// Len returns the number of loaded chunks.
+func (cm *ChunkedMap) Len() int {
+	return len(cm.chunks)
+}
+
+// At returns the cell at loc. The boolean return value is false if the chunk
+// containing loc is not loaded, in which case the zero cell is returned. At
+// never creates chunks.
+func (cm *ChunkedMap) At(loc Location) (Cell, bool) {
+	chunk := cm.chunks[cm.ChunkLoc(loc)]
+	if chunk == nil {
+		return 0, false
+	}
+	return chunk.Cells[loc.Col-chunk.Origin.Col][loc.Row-chunk.Origin.Row], true
+}
+
+// Set sets the cell at loc, creating the chunk containing loc if not loaded.
+func (cm *ChunkedMap) Set(loc Location, cell Cell) error {
+	chunk := cm.load(cm.ChunkLoc(loc))
+	chunk.Cells[loc.Col-chunk.Origin.Col][loc.Row-chunk.Origin.Row] = cell
+	return nil
+}
+
+// Load returns the chunk at the chunk location cloc, creating it if not
+// loaded.
+func (cm *ChunkedMap) Load(cloc Location) *Chunk {
+	return cm.load(cloc)
+}
+
+// load returns the chunk at the chunk location cloc, creating and generating it
+// if not loaded.
+func (cm *ChunkedMap) load(cloc Location) *Chunk {
+	if chunk, ok := cm.chunks[cloc]; ok {
+		return chunk
+	}
+	chunk := &Chunk{
+		Loc:    cloc,
+		Origin: Loc(cloc.Col*cm.ChunkCols, cloc.Row*cm.ChunkRows),
+		Cells:  make([][]Cell, cm.ChunkCols),
+	}
+	for col := range chunk.Cells {
+		chunk.Cells[col] = make([]Cell, cm.ChunkRows)
+	}
+	if cm.Generate != nil {
+		cm.Generate(chunk)
+	}
+	cm.chunks[cloc] = chunk
+	return chunk
+}
+
+// ChunkRange returns the inclusive range of chunk locations of the chunks which
+// contain the cells in the inclusive range between min and max.
+func (cm *ChunkedMap) ChunkRange(min, max Location) (cmin, cmax Location) {
+	return cm.ChunkLoc(min), cm.ChunkLoc(max)
+}
+
+// EachChunk invokes fn for each loaded chunk containing cells in the inclusive
+// range between min and max, in row-major order of chunk locations.
+func (cm *ChunkedMap) EachChunk(min, max Location, fn func(chunk *Chunk)) {
+	cmin, cmax := cm.ChunkRange(min, max)
+	for row := cmin.Row; row <= cmax.Row; row++ {
+		for col := cmin.Col; col <= cmax.Col; col++ {
+			if chunk, ok := cm.chunks[Loc(col, row)]; ok {
+				fn(chunk)
+			}
+		}
+	}
+}
+
+// LoadRange loads every chunk containing cells in the inclusive range between
+// min and max, creating chunks as required.
+func (cm *ChunkedMap) LoadRange(min, max Location) {
+	cmin, cmax := cm.ChunkRange(min, max)
+	for row := cmin.Row; row <= cmax.Row; row++ {
+		for col := cmin.Col; col <= cmax.Col; col++ {
+			cm.load(Loc(col, row))
+		}
+	}
+}
+
+// Unload unloads every chunk which is further than margin chunks away from the
+// chunks containing the cells in the inclusive range between min and max, such
+// as the cells visible through a view. The unloaded chunks are returned in
+// row-major order of chunk locations, so that they may be persisted.
+func (cm *ChunkedMap) Unload(min, max Location, margin int) []*Chunk {
+	cmin, cmax := cm.ChunkRange(min, max)
+	keep := image.Rect(cmin.Col-margin, cmin.Row-margin, cmax.Col+margin+1, cmax.Row+margin+1)
+	var unloaded []*Chunk
+	for cloc, chunk := range cm.chunks {
+		if !image.Pt(cloc.Col, cloc.Row).In(keep) {
+			unloaded = append(unloaded, chunk)
+			delete(cm.chunks, cloc)
+		}
+	}
+	sort.Slice(unloaded, func(i, j int) bool {
+		a, b := unloaded[i].Loc, unloaded[j].Loc
+		if a.Row != b.Row {
+			return a.Row < b.Row
+		}
+		return a.Col < b.Col
+	})
+	return unloaded
+}
+
+// Bounds returns the pixel bounds of the cells of the loaded chunks, or an
+// empty rectangle if no chunks are loaded. The bounds may extend into negative
+// coordinates.
+func (cm *ChunkedMap) Bounds() image.Rectangle {
+	var bounds image.Rectangle
+	for _, chunk := range cm.chunks {
+		r := image.Rectangle{Max: cm.Size(cm.ChunkCols, cm.ChunkRows)}.Add(cm.Pixel(chunk.Origin))
+		bounds = bounds.Union(r)
+	}
+	return bounds
+}
@@ -0,0 +1,151 @@
+package grid
+
+import (
+	"fmt"
+)
+
+// Fill sets every cell of the map within r to cell. It is an error for r to
+// extend outside of the map.
+func (m *Map) Fill(r Rect, cell Cell) error {
+	if !r.In(m) {
+		return fmt.Errorf("grid: region %v outside of %dx%d map", r, m.Cols(), m.Rows())
+	}
+	for col := r.Min.Col; col < r.Max.Col; col++ {
+		for row := r.Min.Row; row < r.Max.Row; row++ {
+			m.Cells[col][row] = cell
+		}
+	}
+	return nil
+}
+
+// Copy returns a new map holding a copy of the cells of the map within r, with
+// the geometry and tile sets of m. It is an error for r to extend outside of
+// the map; clip r to the map using r.Intersect(m.Rect()) to copy the cells
+// within the map only.
+func (m *Map) Copy(r Rect) (dst *Map, err error) {
+	if !r.In(m) {
+		return nil, fmt.Errorf("grid: region %v outside of %dx%d map", r, m.Cols(), m.Rows())
+	}
+	dst = NewMap(r.Cols(), r.Rows(), m.Geometry)
+	dst.TileSets = append([]TileSetRef(nil), m.TileSets...)
+	for col := range dst.Cells {
+		copy(dst.Cells[col], m.Cells[r.Min.Col+col][r.Min.Row:r.Max.Row])
+	}
+	return dst, nil
+}
+
+// Paste copies the cells of src into the map, with the top left cell of src
+// placed at loc. Cells of src which would land outside of the map are ignored.
+// The source may be the map itself, for instance to move a region of the map.
+func (m *Map) Paste(src *Map, loc Location) {
+	if src == m {
+		// Copy the cells first, as the source and destination overlap.
+		src, _ = m.Copy(m.Rect())
+	}
+	r := src.Rect().Add(loc).Intersect(m.Rect())
+	for col := r.Min.Col; col < r.Max.Col; col++ {
+		srcCol := src.Cells[col-loc.Col]
+		copy(m.Cells[col][r.Min.Row:r.Max.Row], srcCol[r.Min.Row-loc.Row:r.Max.Row-loc.Row])
+	}
+}
+
+// An Anchor specifies the edge or corner of a map which is kept in place when
+// resizing the map.
+type Anchor int
+
+// Anchors.
+const (
+	TopLeft Anchor = iota
+	Top
+	TopRight
+	Left
+	Center
+	Right
+	BottomLeft
+	Bottom
+	BottomRight
+)
+
+// Resize resizes the map to the specified number of columns and rows. The
+// anchor specifies where the existing cells are kept; e.g. TopLeft adds or
+// removes columns and rows at the right and bottom edges of the map, while
+// Center divides them evenly between opposite edges. Added cells are zero. It
+// is an error for the number of columns or rows not to be positive.
+func (m *Map) Resize(cols, rows int, anchor Anchor) error {
+	if cols <= 0 || rows <= 0 {
+		return fmt.Errorf("grid: invalid map size %dx%d", cols, rows)
+	}
+	if anchor < TopLeft || anchor > BottomRight {
+		return fmt.Errorf("grid: invalid anchor %d", anchor)
+	}
+	// Fractions of the added columns and rows inserted before the existing
+	// cells, in halves.
+	fcol, frow := int(anchor)%3, int(anchor)/3
+	off := Loc((cols-m.Cols())*fcol/2, (rows-m.Rows())*frow/2)
+	dst := NewMap(cols, rows, m.Geometry)
+	dst.Paste(m, off)
+	m.Cells = dst.Cells
+	return nil
+}
+
+// Clone returns a deep copy of the map.
+func (m *Map) Clone() *Map {
+	dst, _ := m.Copy(m.Rect())
+	dst.Wrap = m.Wrap
+	return dst
+}
+
+// Equal returns true if the maps have the same dimensions, geometry, wrapping
+// axes, tile sets and cells.
+func (m *Map) Equal(other *Map) bool {
+	if m.Cols() != other.Cols() || m.Rows() != other.Rows() || m.Geometry != other.Geometry || m.Wrap != other.Wrap {
+		return false
+	}
+	if len(m.TileSets) != len(other.TileSets) {
+		return false
+	}
+	for i := range m.TileSets {
+		if m.TileSets[i] != other.TileSets[i] {
+			return false
+		}
+	}
+	for col := range m.Cells {
+		for row := range m.Cells[col] {
+			if m.Cells[col][row] != other.Cells[col][row] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// A Change records a cell which differs between two maps.
+type Change struct {
+	// Location of the cell.
+	Loc Location
+	// Cell of the old and new map.
+	Old, New Cell
+}
+
+// Diff returns the cells which differ between the map m and the map to, in
+// column-major order. Cells outside of either map are compared as zero cells.
+func (m *Map) Diff(to *Map) []Change {
+	var changes []Change
+	r := m.Rect().Union(to.Rect())
+	for col := r.Min.Col; col < r.Max.Col; col++ {
+		for row := r.Min.Row; row < r.Max.Row; row++ {
+			loc := Loc(col, row)
+			var old, cur Cell
+			if loc.In(m) {
+				old = m.Cells[col][row]
+			}
+			if loc.In(to) {
+				cur = to.Cells[col][row]
+			}
+			if old != cur {
+				changes = append(changes, Change{Loc: loc, Old: old, New: cur})
+			}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,299 @@
+package grid
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// magic identifies binary encoded maps.
+const magic = "pggmap"
+
+// version is the version of the binary encoding.
+const version = 1
+
+// maxCells is the maximum number of cells of a decoded map, which guards
+// against excessive allocations caused by corrupt input.
+const maxCells = 1 << 24
+
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding is compact
+// and versioned; the cells are run-length encoded in row-major order and
+// compressed using zlib.
+func (m *Map) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteString(magic)
+	buf.WriteByte(version)
+	putUvarint(buf, uint64(m.Cols()))
+	putUvarint(buf, uint64(m.Rows()))
+	putUvarint(buf, uint64(m.CellWidth))
+	putUvarint(buf, uint64(m.CellHeight))
+	buf.WriteByte(byte(m.Wrap))
+	putUvarint(buf, uint64(len(m.TileSets)))
+	for _, ts := range m.TileSets {
+		putVarint(buf, int64(ts.FirstID))
+		putUvarint(buf, uint64(ts.TileWidth))
+		putUvarint(buf, uint64(ts.TileHeight))
+		putUvarint(buf, uint64(len(ts.Path)))
+		buf.WriteString(ts.Path)
+	}
+	// Run-length encode the cells as pairs of run lengths and cells.
+	var runs bytes.Buffer
+	var run uint64
+	var prev Cell
+	for row := 0; row < m.Rows(); row++ {
+		for col := 0; col < m.Cols(); col++ {
+			cell := m.Cells[col][row]
+			if run > 0 && cell != prev {
+				putUvarint(&runs, run)
+				putVarint(&runs, int64(prev))
+				run = 0
+			}
+			prev = cell
+			run++
+		}
+	}
+	if run > 0 {
+		putUvarint(&runs, run)
+		putVarint(&runs, int64(prev))
+	}
+	zw := zlib.NewWriter(buf)
+	if _, err := zw.Write(runs.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (m *Map) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	hdr := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(r, hdr); err != nil || string(hdr[:len(magic)]) != magic {
+		return errors.New("grid: invalid header")
+	}
+	if v := hdr[len(magic)]; v != version {
+		return fmt.Errorf("grid: unsupported version %d", v)
+	}
+	var dims [4]uint64
+	for i, name := range []string{"number of columns", "number of rows", "cell width", "cell height"} {
+		x, err := binary.ReadUvarint(r)
+		if err != nil {
+			return fmt.Errorf("grid: invalid %s; %v", name, err)
+		}
+		dims[i] = x
+	}
+	cols, rows := dims[0], dims[1]
+	if cols == 0 || rows == 0 {
+		// Maps without cells.
+		cols, rows = 0, 0
+	}
+	if cols > maxCells || rows > maxCells || cols*rows > maxCells {
+		return fmt.Errorf("grid: invalid dimensions %dx%d", dims[0], dims[1])
+	}
+	if dims[2] > maxCells || dims[3] > maxCells {
+		return fmt.Errorf("grid: invalid cell dimensions %dx%d", dims[2], dims[3])
+	}
+	wrap, err := r.ReadByte()
+	if err != nil || Wrap(wrap)&^WrapBoth != 0 {
+		return errors.New("grid: invalid wrap axes")
+	}
+	tileSets, err := readTileSetRefs(r)
+	if err != nil {
+		return err
+	}
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("grid: invalid cell data; %v", err)
+	}
+	defer zr.Close()
+	br := bufio.NewReader(zr)
+	// Decode the runs before allocating the map, so that the size of the
+	// allocation is bounded by the cell data actually present.
+	type cellRun struct {
+		n    uint64
+		cell Cell
+	}
+	var runs []cellRun
+	total := cols * rows
+	for i := uint64(0); i < total; {
+		run, err := binary.ReadUvarint(br)
+		if err != nil {
+			return fmt.Errorf("grid: invalid cell data; %v", err)
+		}
+		cell, err := binary.ReadVarint(br)
+		if err != nil {
+			return fmt.Errorf("grid: invalid cell data; %v", err)
+		}
+		if run == 0 || run > total-i {
+			return fmt.Errorf("grid: invalid run length %d at cell %d of %d", run, i, total)
+		}
+		runs = append(runs, cellRun{n: run, cell: Cell(cell)})
+		i += run
+	}
+	if _, err := br.ReadByte(); err != io.EOF {
+		return errors.New("grid: trailing cell data")
+	}
+	dec := NewMap(int(cols), int(rows), Geometry{CellWidth: int(dims[2]), CellHeight: int(dims[3])})
+	dec.Wrap = Wrap(wrap)
+	dec.TileSets = tileSets
+	i := uint64(0)
+	for _, run := range runs {
+		for end := i + run.n; i < end; i++ {
+			dec.Cells[i%cols][i/cols] = run.cell
+		}
+	}
+	*m = *dec
+	return nil
+}
+
+// readTileSetRefs reads the binary encoded tile set references from r.
+func readTileSetRefs(r *bytes.Reader) ([]TileSetRef, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil || n > uint64(r.Len()) {
+		return nil, errors.New("grid: invalid number of tile sets")
+	}
+	var refs []TileSetRef
+	for i := uint64(0); i < n; i++ {
+		firstID, err := binary.ReadVarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("grid: invalid first global tile identifier of tile set %d; %v", i, err)
+		}
+		var vals [3]uint64
+		for j := range vals {
+			vals[j], err = binary.ReadUvarint(r)
+			if err != nil || vals[j] > maxCells {
+				return nil, fmt.Errorf("grid: invalid tile set %d", i)
+			}
+		}
+		if vals[2] > uint64(r.Len()) {
+			return nil, fmt.Errorf("grid: invalid path length %d of tile set %d", vals[2], i)
+		}
+		path := make([]byte, vals[2])
+		if _, err := io.ReadFull(r, path); err != nil {
+			return nil, fmt.Errorf("grid: invalid path of tile set %d; %v", i, err)
+		}
+		ref := TileSetRef{
+			FirstID:    Cell(firstID),
+			Path:       string(path),
+			TileWidth:  int(vals[0]),
+			TileHeight: int(vals[1]),
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// putUvarint writes the unsigned varint encoding of x to buf.
+func putUvarint(buf *bytes.Buffer, x uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], x)
+	buf.Write(tmp[:n])
+}
+
+// putVarint writes the signed varint encoding of x to buf.
+func putVarint(buf *bytes.Buffer, x int64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(tmp[:], x)
+	buf.Write(tmp[:n])
+}
+
+// jsonMap is the JSON representation of a map. Cells are stored as one array
+// per row, so that the layout of the JSON document resembles the map.
+type jsonMap struct {
+	Cols       int          `json:"cols"`
+	Rows       int          `json:"rows"`
+	CellWidth  int          `json:"cellWidth"`
+	CellHeight int          `json:"cellHeight"`
+	Wrap       Wrap         `json:"wrap,omitempty"`
+	TileSets   []TileSetRef `json:"tileSets,omitempty"`
+	Cells      [][]Cell     `json:"cells"`
+}
+
+// MarshalJSON implements json.Marshaler. The JSON encoding is human-readable;
+// cells are stored in rows.
+func (m *Map) MarshalJSON() ([]byte, error) {
+	jm := jsonMap{
+		Cols:       m.Cols(),
+		Rows:       m.Rows(),
+		CellWidth:  m.CellWidth,
+		CellHeight: m.CellHeight,
+		Wrap:       m.Wrap,
+		TileSets:   m.TileSets,
+		Cells:      make([][]Cell, m.Rows()),
+	}
+	for row := range jm.Cells {
+		jm.Cells[row] = make([]Cell, m.Cols())
+		for col := range jm.Cells[row] {
+			jm.Cells[row][col] = m.Cells[col][row]
+		}
+	}
+	return json.Marshal(jm)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (m *Map) UnmarshalJSON(data []byte) error {
+	var jm jsonMap
+	if err := json.Unmarshal(data, &jm); err != nil {
+		return err
+	}
+	if jm.Cols < 0 || jm.Rows < 0 || jm.CellWidth < 0 || jm.CellHeight < 0 {
+		return fmt.Errorf("grid: invalid dimensions %dx%d with %dx%d cells", jm.Cols, jm.Rows, jm.CellWidth, jm.CellHeight)
+	}
+	if len(jm.Cells) != jm.Rows {
+		return fmt.Errorf("grid: number of cell rows %d differs from map rows %d", len(jm.Cells), jm.Rows)
+	}
+	for row, cells := range jm.Cells {
+		if len(cells) != jm.Cols {
+			return fmt.Errorf("grid: row %d has %d cells; expected %d", row, len(cells), jm.Cols)
+		}
+	}
+	if jm.Cols == 0 || jm.Rows == 0 {
+		// Maps without cells.
+		jm.Cols, jm.Rows = 0, 0
+	}
+	dec := NewMap(jm.Cols, jm.Rows, Geometry{CellWidth: jm.CellWidth, CellHeight: jm.CellHeight})
+	dec.Wrap = jm.Wrap
+	dec.TileSets = jm.TileSets
+	for row, cells := range jm.Cells {
+		for col, cell := range cells {
+			dec.Cells[col][row] = cell
+		}
+	}
+	*m = *dec
+	return nil
+}
+
+// wrapNames maps wrap axes to their textual representation.
+var wrapNames = map[Wrap]string{
+	0:              "none",
+	WrapHorizontal: "horizontal",
+	WrapVertical:   "vertical",
+	WrapBoth:       "both",
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (w Wrap) MarshalText() ([]byte, error) {
+	name, ok := wrapNames[w]
+	if !ok {
+		return nil, fmt.Errorf("grid: invalid wrap axes %d", w)
+	}
+	return []byte(name), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (w *Wrap) UnmarshalText(text []byte) error {
+	for wrap, name := range wrapNames {
+		if name == string(text) {
+			*w = wrap
+			return nil
+		}
+	}
+	return fmt.Errorf("grid: invalid wrap axes %q", text)
+}
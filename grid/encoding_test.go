@@ -0,0 +1,175 @@
+package grid
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+)
+
+// testMaps returns maps covering the features of the map encodings.
+func testMaps() []*Map {
+	empty := NewMap(0, 0, DefaultGeometry())
+
+	small := NewMap(3, 2, Geometry{CellWidth: 16, CellHeight: 24})
+	small.Cells[0][0] = 1
+	small.Cells[2][1] = 7
+
+	wrapped := NewMap(5, 4, DefaultGeometry())
+	wrapped.Wrap = WrapHorizontal
+	for col := 1; col < 4; col++ {
+		wrapped.Cells[col][1] = 3
+		wrapped.Cells[col][2] = 3
+	}
+	wrapped.TileSets = []TileSetRef{
+		{FirstID: 1, Path: "ground.png", TileWidth: 32, TileHeight: 32},
+		{FirstID: 65, Path: "overhead.png", TileWidth: 32, TileHeight: 64},
+	}
+
+	negative := NewMap(2, 2, DefaultGeometry())
+	negative.Wrap = WrapBoth
+	negative.Cells[1][0] = -1
+
+	return []*Map{empty, small, wrapped, negative}
+}
+
+// equal returns true if the maps a and b have the same dimensions, geometry,
+// wrapping axes, tile sets and cells.
+func equal(a, b *Map) bool {
+	if a.Cols() != b.Cols() || a.Rows() != b.Rows() || a.Geometry != b.Geometry || a.Wrap != b.Wrap {
+		return false
+	}
+	if len(a.TileSets) != len(b.TileSets) {
+		return false
+	}
+	for i := range a.TileSets {
+		if a.TileSets[i] != b.TileSets[i] {
+			return false
+		}
+	}
+	for col := range a.Cells {
+		for row := range a.Cells[col] {
+			if a.Cells[col][row] != b.Cells[col][row] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestRoundTrip(t *testing.T) {
+	for i, m := range testMaps() {
+		buf, err := m.MarshalBinary()
+		if err != nil {
+			t.Errorf("map %d: unable to marshal binary; %v", i, err)
+			continue
+		}
+		dec := new(Map)
+		if err := dec.UnmarshalBinary(buf); err != nil {
+			t.Errorf("map %d: unable to unmarshal binary; %v", i, err)
+		} else if !equal(dec, m) {
+			t.Errorf("map %d: binary round-trip mismatch; expected %+v, got %+v", i, m, dec)
+		}
+		if again, err := dec.MarshalBinary(); err != nil || !bytes.Equal(again, buf) {
+			t.Errorf("map %d: binary re-encoding mismatch; expected %q, got %q (%v)", i, buf, again, err)
+		}
+
+		buf, err = m.MarshalJSON()
+		if err != nil {
+			t.Errorf("map %d: unable to marshal JSON; %v", i, err)
+			continue
+		}
+		dec = new(Map)
+		if err := dec.UnmarshalJSON(buf); err != nil {
+			t.Errorf("map %d: unable to unmarshal JSON; %v", i, err)
+		} else if !equal(dec, m) {
+			t.Errorf("map %d: JSON round-trip mismatch; expected %+v, got %+v", i, m, dec)
+		}
+		if again, err := dec.MarshalJSON(); err != nil || !bytes.Equal(again, buf) {
+			t.Errorf("map %d: JSON re-encoding mismatch; expected %q, got %q (%v)", i, buf, again, err)
+		}
+	}
+}
+
+func TestUnmarshalBinaryLarge(t *testing.T) {
+	// Header of a 4096x4096 map without cell data.
+	data := []byte("pggmap\x01\x80\x20\x80\x20\x20\x20\x00\x00")
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if err := new(Map).UnmarshalBinary(data); err == nil {
+		t.Fatal("expected error for missing cell data")
+	}
+	runtime.ReadMemStats(&after)
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Errorf("allocated %d bytes before reading the cell data", n)
+	}
+}
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	for _, m := range testMaps() {
+		buf, err := m.MarshalBinary()
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(buf)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		m := new(Map)
+		if err := m.UnmarshalBinary(data); err != nil {
+			return
+		}
+		// The encoding of a decoded map is canonical; it decodes to the same map
+		// and re-encodes to the same bytes.
+		buf, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatalf("unable to marshal decoded map; %v", err)
+		}
+		dec := new(Map)
+		if err := dec.UnmarshalBinary(buf); err != nil {
+			t.Fatalf("unable to unmarshal re-encoded map; %v", err)
+		}
+		if !equal(dec, m) {
+			t.Fatalf("map mismatch; expected %+v, got %+v", m, dec)
+		}
+		again, err := dec.MarshalBinary()
+		if err != nil {
+			t.Fatalf("unable to marshal re-decoded map; %v", err)
+		}
+		if !bytes.Equal(again, buf) {
+			t.Fatalf("encoding mismatch; expected %x, got %x", buf, again)
+		}
+	})
+}
+
+func FuzzUnmarshalJSON(f *testing.F) {
+	for _, m := range testMaps() {
+		buf, err := m.MarshalJSON()
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(buf)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		m := new(Map)
+		if err := m.UnmarshalJSON(data); err != nil {
+			return
+		}
+		buf, err := m.MarshalJSON()
+		if err != nil {
+			t.Fatalf("unable to marshal decoded map; %v", err)
+		}
+		dec := new(Map)
+		if err := dec.UnmarshalJSON(buf); err != nil {
+			t.Fatalf("unable to unmarshal re-encoded map; %v", err)
+		}
+		if !equal(dec, m) {
+			t.Fatalf("map mismatch; expected %+v, got %+v", m, dec)
+		}
+		again, err := dec.MarshalJSON()
+		if err != nil {
+			t.Fatalf("unable to marshal re-decoded map; %v", err)
+		}
+		if !bytes.Equal(again, buf) {
+			t.Fatalf("encoding mismatch; expected %s, got %s", buf, again)
+		}
+	})
+}
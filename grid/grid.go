@@ -1,67 +1,141 @@
 // Package grid divides the game world into a series of contiguous grid cells
 // using regular tessellation.
 //
-// Remember to specify the grid cell dimensions during init since they affect
-// other logic.
+// The grid cell dimensions are specified per map by its geometry.
 package grid
 
 import (
+	"fmt"
+	"image"
+
 	"github.com/mewmew/pgg/tileset"
 )
 
 // A Map is a collection of cells that forms a complete map. It consists of a
-// two-dimensional array with column and row indexes.
-type Map [][]Cell
-
-// NewMap returns a new map with the specified number of columns and rows.
-func NewMap(cols, rows int) (m Map) {
-	m = make([][]Cell, cols)
-	for col := range m {
-		m[col] = make([]Cell, rows)
+// two-dimensional array with column and row indexes, and the geometry of its
+// cells.
+type Map struct {
+	// Map cells, indexed by column and row.
+	Cells [][]Cell
+	// Cell geometry of the map.
+	Geometry
+	// Axes along which the map wraps around.
+	Wrap Wrap
+	// Tile sets referenced by the cells of the map, if any.
+	TileSets []TileSetRef
+}
+
+// A TileSetRef references a tile set of a map, which is assigned the range of
+// global tile identifiers starting at FirstID.
+type TileSetRef struct {
+	// First global tile identifier of the tile set.
+	FirstID Cell `json:"firstID"`
+	// Path to the sprite sheet of the tile set.
+	Path string `json:"path"`
+	// Tile width and height of the tile set in pixels.
+	TileWidth  int `json:"tileWidth"`
+	TileHeight int `json:"tileHeight"`
+}
+
+// NewMap returns a new map with the specified number of columns and rows, the
+// cells of which have the provided geometry.
+func NewMap(cols, rows int, geom Geometry) (m *Map) {
+	m = &Map{
+		Cells:    make([][]Cell, cols),
+		Geometry: geom,
+	}
+	for col := range m.Cells {
+		m.Cells[col] = make([]Cell, rows)
 	}
 	return m
 }
 
 // Cols returns the number of columns in the map.
-func (m Map) Cols() int {
-	return len(m)
+func (m *Map) Cols() int {
+	return len(m.Cells)
 }
 
 // Rows returns the number of rows in the map.
-func (m Map) Rows() int {
+func (m *Map) Rows() int {
 	if m.Cols() < 1 {
 		return 0
 	}
-	return len(m[0])
+	return len(m.Cells[0])
+}
+
+// Bounds returns the pixel bounds of the map. The top left point of the map is
+// always located at (0, 0).
+func (m *Map) Bounds() image.Rectangle {
+	return image.Rectangle{Max: m.Size(m.Cols(), m.Rows())}
+}
+
+// At returns the cell at loc, wrapped around the wrapping axes of the map. The
+// boolean return value is false if loc is outside of the map.
+func (m *Map) At(loc Location) (cell Cell, ok bool) {
+	loc, ok = m.Normalize(loc)
+	if !ok {
+		return 0, false
+	}
+	return m.Cells[loc.Col][loc.Row], true
+}
+
+// Set sets the cell at loc, wrapped around the wrapping axes of the map. It is
+// an error for loc to be outside of the map.
+func (m *Map) Set(loc Location, cell Cell) error {
+	norm, ok := m.Normalize(loc)
+	if !ok {
+		return fmt.Errorf("grid: location (%d, %d) outside of %dx%d map", loc.Col, loc.Row, m.Cols(), m.Rows())
+	}
+	m.Cells[norm.Col][norm.Row] = cell
+	return nil
+}
+
+// A Store provides access to the cells of a map, such as the dense cells of a
+// Map or the sparse chunks of a ChunkedMap.
+type Store interface {
+	// At returns the cell at loc. The boolean return value is false if no
+	// cell is stored at loc.
+	At(loc Location) (Cell, bool)
+	// Set sets the cell at loc.
+	Set(loc Location, cell Cell) error
 }
 
+// Maps and chunked maps are stores.
+var (
+	_ Store = (*Map)(nil)
+	_ Store = (*ChunkedMap)(nil)
+)
+
 // A Cell corresponds to an individual grid cell which covers a portion of the
-// grid. The area of a cell is specified by CellWidth and CellHeight.
-type Cell tileset.TileID
+// grid. The area of a cell is specified by the geometry of its map. The tile
+// identifier of a cell is understood by the tile sets of all graphics backends.
+type Cell = tileset.TileID
+
+// A Geometry specifies the dimensions of grid cells.
+type Geometry struct {
+	// Cell width in pixels.
+	CellWidth int
+	// Cell height in pixels.
+	CellHeight int
+}
 
-// The width and height of grid cells. Remember to specify these dimensions
-// during init since they affect other logic.
+// The default width and height of grid cells, as used by DefaultGeometry.
 var (
 	CellWidth  = 32
 	CellHeight = 32
 )
 
-// TODO(u): define Col and Row types?
-
-// A Location specifies a precise grid location corresponding to a specific cell
-// of the map.
-type Location struct {
-	// The grid column.
-	Col int
-	// The grid row.
-	Row int
+// DefaultGeometry returns a geometry with the default cell dimensions specified
+// by CellWidth and CellHeight.
+func DefaultGeometry() Geometry {
+	return Geometry{
+		CellWidth:  CellWidth,
+		CellHeight: CellHeight,
+	}
 }
 
-// Loc is shorthand for Location{col, row}.
-func Loc(col, row int) (loc Location) {
-	loc = Location{
-		Col: col,
-		Row: row,
-	}
-	return loc
+// Size returns the pixel dimensions of an area covered by the specified number
+// of columns and rows.
+func (geom Geometry) Size(cols, rows int) image.Point {
+	return image.Pt(cols*geom.CellWidth, rows*geom.CellHeight)
 }
@@ -0,0 +1,90 @@
+package grid
+
+// Line invokes fn for each location of the Bresenham line from a to b, in
+// order, including both a and b.
+func Line(a, b Location, fn func(loc Location)) {
+	dx := abs(b.Col - a.Col)
+	dy := -abs(b.Row - a.Row)
+	sx, sy := 1, 1
+	if a.Col > b.Col {
+		sx = -1
+	}
+	if a.Row > b.Row {
+		sy = -1
+	}
+	err := dx + dy
+	for loc := a; ; {
+		fn(loc)
+		if loc == b {
+			break
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			loc.Col += sx
+		}
+		if e2 <= dx {
+			err += dx
+			loc.Row += sy
+		}
+	}
+}
+
+// Circle invokes fn for each location within the Euclidean distance radius of
+// center, in row-major order.
+func Circle(center Location, radius int, fn func(loc Location)) {
+	for drow := -radius; drow <= radius; drow++ {
+		for dcol := -radius; dcol <= radius; dcol++ {
+			if dcol*dcol+drow*drow <= radius*radius {
+				fn(Loc(center.Col+dcol, center.Row+drow))
+			}
+		}
+	}
+}
+
+// Spiral invokes fn for each location within the Chebyshev distance radius of
+// center, in order of increasing distance; starting at center, followed by
+// each square ring around it clockwise from its top left corner.
+func Spiral(center Location, radius int, fn func(loc Location)) {
+	if radius < 0 {
+		return
+	}
+	// Walk east, south, west and north along the sides of each ring.
+	sides := [4]Location{Directions[1], Directions[2], Directions[3], Directions[0]}
+	fn(center)
+	for r := 1; r <= radius; r++ {
+		loc := Loc(center.Col-r, center.Row-r)
+		for _, d := range sides {
+			for i := 0; i < 2*r; i++ {
+				fn(loc)
+				loc = loc.Add(d)
+			}
+		}
+	}
+}
+
+// Flood invokes fn for each location connected to start through edge
+// neighbours, the cells of which are matched by match; including start itself
+// if matched. Locations are wrapped around the wrapping axes of the map and
+// passed to fn normalized, in breadth-first order.
+func (m *Map) Flood(start Location, match func(cell Cell) bool, fn func(loc Location)) {
+	start, ok := m.Normalize(start)
+	if !ok || !match(m.Cells[start.Col][start.Row]) {
+		return
+	}
+	seen := map[Location]bool{start: true}
+	queue := []Location{start}
+	for len(queue) > 0 {
+		loc := queue[0]
+		queue = queue[1:]
+		fn(loc)
+		for _, neighbor := range loc.Neighbors4() {
+			neighbor, ok := m.Normalize(neighbor)
+			if !ok || seen[neighbor] || !match(m.Cells[neighbor.Col][neighbor.Row]) {
+				continue
+			}
+			seen[neighbor] = true
+			queue = append(queue, neighbor)
+		}
+	}
+}
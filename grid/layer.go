@@ -0,0 +1,107 @@
+package grid
+
+import (
+	"image"
+	"sort"
+)
+
+// A LayeredMap is a map made up of ordered layers which are composited bottom
+// to top. All layers of a layered map have the same dimensions and geometry.
+type LayeredMap struct {
+	// Map layers.
+	Layers []*Layer
+	// The width and height of the map in number of columns and rows
+	// respectively.
+	cols, rows int
+	// Cell geometry of the map.
+	Geometry
+	// Axes along which the map wraps around; inherited by layers when added.
+	Wrap Wrap
+}
+
+// A Layer is a named layer of a layered map.
+type Layer struct {
+	// Layer name.
+	Name string
+	// Layer opacity in the range [0, 1].
+	Opacity float64
+	// Specifies whether the layer is visible.
+	Visible bool
+	// Draw order of the layer; layers with a lower draw order are drawn before,
+	// and thereby below, layers with a higher draw order.
+	Order int
+	// Layer cells.
+	*Map
+}
+
+// NewLayeredMap returns a new layered map without layers of the specified
+// number of columns and rows, the cells of which have the provided geometry.
+func NewLayeredMap(cols, rows int, geom Geometry) (lm *LayeredMap) {
+	lm = &LayeredMap{
+		cols:     cols,
+		rows:     rows,
+		Geometry: geom,
+	}
+	return lm
+}
+
+// AddLayer adds a new visible and opaque layer with the provided name on top of
+// the existing layers of the map.
+func (lm *LayeredMap) AddLayer(name string) (l *Layer) {
+	l = &Layer{
+		Name:    name,
+		Opacity: 1,
+		Visible: true,
+		Map:     NewMap(lm.cols, lm.rows, lm.Geometry),
+	}
+	l.Wrap = lm.Wrap
+	for _, prev := range lm.Layers {
+		if prev.Order >= l.Order {
+			l.Order = prev.Order + 1
+		}
+	}
+	lm.Layers = append(lm.Layers, l)
+	return l
+}
+
+// Layer returns the first layer with the specified name, or nil if no such
+// layer exists.
+func (lm *LayeredMap) Layer(name string) *Layer {
+	for _, l := range lm.Layers {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// DrawOrder returns the visible layers of the map sorted bottom to top by draw
+// order. Layers of equal draw order retain their relative order.
+func (lm *LayeredMap) DrawOrder() []*Layer {
+	var layers []*Layer
+	for _, l := range lm.Layers {
+		if l.Visible && l.Opacity > 0 {
+			layers = append(layers, l)
+		}
+	}
+	sort.SliceStable(layers, func(i, j int) bool {
+		return layers[i].Order < layers[j].Order
+	})
+	return layers
+}
+
+// Cols returns the number of columns in the map.
+func (lm *LayeredMap) Cols() int {
+	return lm.cols
+}
+
+// Rows returns the number of rows in the map.
+func (lm *LayeredMap) Rows() int {
+	return lm.rows
+}
+
+// Bounds returns the pixel bounds of the map. The top left point of the map is
+// always located at (0, 0).
+func (lm *LayeredMap) Bounds() image.Rectangle {
+	return image.Rectangle{Max: lm.Size(lm.cols, lm.rows)}
+}
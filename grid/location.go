@@ -0,0 +1,108 @@
+package grid
+
+import (
+	"math"
+)
+
+// A Location specifies a precise grid location corresponding to a specific cell
+// of the map. Locations double as column and row offsets between cells, as used
+// by Add and Sub.
+type Location struct {
+	// The grid column.
+	Col int
+	// The grid row.
+	Row int
+}
+
+// Loc is shorthand for Location{col, row}.
+func Loc(col, row int) (loc Location) {
+	loc = Location{
+		Col: col,
+		Row: row,
+	}
+	return loc
+}
+
+// Add returns the location loc+d.
+func (loc Location) Add(d Location) Location {
+	return Loc(loc.Col+d.Col, loc.Row+d.Row)
+}
+
+// Sub returns the location loc-d.
+func (loc Location) Sub(d Location) Location {
+	return Loc(loc.Col-d.Col, loc.Row-d.Row)
+}
+
+// Directions holds the column and row offsets of the edge neighbours of a cell
+// (north, east, south and west) followed by its corner neighbours (north-east,
+// south-east, south-west and north-west).
+var Directions = [8]Location{
+	{Col: 0, Row: -1},
+	{Col: 1, Row: 0},
+	{Col: 0, Row: 1},
+	{Col: -1, Row: 0},
+	{Col: 1, Row: -1},
+	{Col: 1, Row: 1},
+	{Col: -1, Row: 1},
+	{Col: -1, Row: -1},
+}
+
+// Neighbors4 returns the locations of the edge neighbours of loc, in the order
+// of Directions.
+func (loc Location) Neighbors4() (neighbors [4]Location) {
+	for i := range neighbors {
+		neighbors[i] = loc.Add(Directions[i])
+	}
+	return neighbors
+}
+
+// Neighbors8 returns the locations of the edge neighbours of loc followed by its
+// corner neighbours, in the order of Directions.
+func (loc Location) Neighbors8() (neighbors [8]Location) {
+	for i := range neighbors {
+		neighbors[i] = loc.Add(Directions[i])
+	}
+	return neighbors
+}
+
+// Manhattan returns the Manhattan distance between loc and other; the number
+// of edge steps between them.
+func (loc Location) Manhattan(other Location) int {
+	dcol, drow := absDelta(loc, other)
+	return dcol + drow
+}
+
+// Chebyshev returns the Chebyshev distance between loc and other; the number of
+// edge or corner steps between them.
+func (loc Location) Chebyshev(other Location) int {
+	dcol, drow := absDelta(loc, other)
+	if dcol > drow {
+		return dcol
+	}
+	return drow
+}
+
+// Euclidean returns the straight-line distance between loc and other.
+func (loc Location) Euclidean(other Location) float64 {
+	dcol, drow := absDelta(loc, other)
+	return math.Hypot(float64(dcol), float64(drow))
+}
+
+// In returns true if loc is located within the columns and rows of m, without
+// wrapping around the wrapping axes of the map.
+func (loc Location) In(m *Map) bool {
+	return loc.Col >= 0 && loc.Col < m.Cols() && loc.Row >= 0 && loc.Row < m.Rows()
+}
+
+// absDelta returns the absolute column and row differences between a and b.
+func absDelta(a, b Location) (dcol, drow int) {
+	return abs(a.Col - b.Col), abs(a.Row - b.Row)
+}
+
+// abs returns the absolute value of x.
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,79 @@
+package grid
+
+import (
+	"image"
+)
+
+// A Projection maps the cells of a tessellation onto world pixels. The world
+// pixel bounds of a cell are the bounds of its tile image, which may overlap the
+// bounds of neighbouring cells for non-square tessellations.
+type Projection interface {
+	// Pixel returns the top left world pixel of the bounds of the cell at loc.
+	Pixel(loc Location) image.Point
+	// CellBounds returns the world pixel bounds of the cell at loc.
+	CellBounds(loc Location) image.Rectangle
+	// Pick returns the location of the cell containing the world pixel p.
+	Pick(p image.Point) Location
+	// Bounds returns the world pixel bounds of a map of the specified number
+	// of columns and rows.
+	Bounds(cols, rows int) image.Rectangle
+	// Range returns the inclusive range of cells whose bounds may intersect
+	// the world pixel rectangle r. The range may extend past the map.
+	Range(r image.Rectangle) (min, max Location)
+	// Order invokes fn for each cell in the inclusive range between min and
+	// max, back to front, so that cells drawn later overlap cells drawn
+	// earlier correctly.
+	Order(min, max Location, fn func(loc Location))
+}
+
+// Geometry is the projection of square tessellations; the cells of which are
+// laid out edge to edge in columns and rows.
+var _ Projection = Geometry{}
+
+// Pixel returns the top left world pixel of the cell at loc.
+func (geom Geometry) Pixel(loc Location) image.Point {
+	return image.Pt(loc.Col*geom.CellWidth, loc.Row*geom.CellHeight)
+}
+
+// CellBounds returns the world pixel bounds of the cell at loc.
+func (geom Geometry) CellBounds(loc Location) image.Rectangle {
+	return image.Rectangle{Max: geom.Size(1, 1)}.Add(geom.Pixel(loc))
+}
+
+// Pick returns the location of the cell containing the world pixel p.
+func (geom Geometry) Pick(p image.Point) Location {
+	return Loc(floorDiv(p.X, geom.CellWidth), floorDiv(p.Y, geom.CellHeight))
+}
+
+// Bounds returns the world pixel bounds of a map of the specified number of
+// columns and rows.
+func (geom Geometry) Bounds(cols, rows int) image.Rectangle {
+	return image.Rectangle{Max: geom.Size(cols, rows)}
+}
+
+// Range returns the inclusive range of cells intersecting the world pixel
+// rectangle r.
+func (geom Geometry) Range(r image.Rectangle) (min, max Location) {
+	min = geom.Pick(r.Min)
+	max = geom.Pick(r.Max.Sub(image.Pt(1, 1)))
+	return min, max
+}
+
+// Order invokes fn for each cell in the inclusive range between min and max,
+// row by row.
+func (geom Geometry) Order(min, max Location, fn func(loc Location)) {
+	for row := min.Row; row <= max.Row; row++ {
+		for col := min.Col; col <= max.Col; col++ {
+			fn(Loc(col, row))
+		}
+	}
+}
+
+// floorDiv returns a/b rounded towards negative infinity, for b > 0.
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
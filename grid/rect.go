@@ -0,0 +1,127 @@
+package grid
+
+import (
+	"fmt"
+)
+
+// A Rect is a rectangular region of grid locations. It contains the locations
+// with Min.Col <= Col < Max.Col and Min.Row <= Row < Max.Row, similar to
+// image.Rectangle. A rect is well-formed if Min.Col <= Max.Col and Min.Row <=
+// Max.Row.
+type Rect struct {
+	// Top left location of the rect.
+	Min Location
+	// Location one past the bottom right location of the rect.
+	Max Location
+}
+
+// Span returns the well-formed rect covering the inclusive range between the
+// locations a and b, such as the ranges returned by Range.
+func Span(a, b Location) (r Rect) {
+	if a.Col > b.Col {
+		a.Col, b.Col = b.Col, a.Col
+	}
+	if a.Row > b.Row {
+		a.Row, b.Row = b.Row, a.Row
+	}
+	r = Rect{
+		Min: a,
+		Max: Loc(b.Col+1, b.Row+1),
+	}
+	return r
+}
+
+// Rect returns the rect covering every cell of the map.
+func (m *Map) Rect() Rect {
+	return Rect{Max: Loc(m.Cols(), m.Rows())}
+}
+
+// String returns a string representation of r, such as "(0, 0)-(3, 2)".
+func (r Rect) String() string {
+	return fmt.Sprintf("(%d, %d)-(%d, %d)", r.Min.Col, r.Min.Row, r.Max.Col, r.Max.Row)
+}
+
+// Cols returns the number of columns of r.
+func (r Rect) Cols() int {
+	return r.Max.Col - r.Min.Col
+}
+
+// Rows returns the number of rows of r.
+func (r Rect) Rows() int {
+	return r.Max.Row - r.Min.Row
+}
+
+// Empty returns true if r contains no locations.
+func (r Rect) Empty() bool {
+	return r.Min.Col >= r.Max.Col || r.Min.Row >= r.Max.Row
+}
+
+// Contains returns true if loc is located within r.
+func (r Rect) Contains(loc Location) bool {
+	return r.Min.Col <= loc.Col && loc.Col < r.Max.Col && r.Min.Row <= loc.Row && loc.Row < r.Max.Row
+}
+
+// In returns true if r is well-formed and located within the columns and rows
+// of m, without wrapping around the wrapping axes of the map.
+func (r Rect) In(m *Map) bool {
+	return 0 <= r.Min.Col && r.Min.Col <= r.Max.Col && r.Max.Col <= m.Cols() && 0 <= r.Min.Row && r.Min.Row <= r.Max.Row && r.Max.Row <= m.Rows()
+}
+
+// Add returns the rect r translated by d.
+func (r Rect) Add(d Location) Rect {
+	return Rect{Min: r.Min.Add(d), Max: r.Max.Add(d)}
+}
+
+// Intersect returns the largest rect contained by both r and s. The zero rect
+// is returned if they do not overlap.
+func (r Rect) Intersect(s Rect) Rect {
+	if r.Min.Col < s.Min.Col {
+		r.Min.Col = s.Min.Col
+	}
+	if r.Min.Row < s.Min.Row {
+		r.Min.Row = s.Min.Row
+	}
+	if r.Max.Col > s.Max.Col {
+		r.Max.Col = s.Max.Col
+	}
+	if r.Max.Row > s.Max.Row {
+		r.Max.Row = s.Max.Row
+	}
+	if r.Empty() {
+		return Rect{}
+	}
+	return r
+}
+
+// Union returns the smallest rect containing both r and s. Empty rects are
+// ignored.
+func (r Rect) Union(s Rect) Rect {
+	if r.Empty() {
+		return s
+	}
+	if s.Empty() {
+		return r
+	}
+	if r.Min.Col > s.Min.Col {
+		r.Min.Col = s.Min.Col
+	}
+	if r.Min.Row > s.Min.Row {
+		r.Min.Row = s.Min.Row
+	}
+	if r.Max.Col < s.Max.Col {
+		r.Max.Col = s.Max.Col
+	}
+	if r.Max.Row < s.Max.Row {
+		r.Max.Row = s.Max.Row
+	}
+	return r
+}
+
+// Each invokes fn for each location of r, in row-major order.
+func (r Rect) Each(fn func(loc Location)) {
+	for row := r.Min.Row; row < r.Max.Row; row++ {
+		for col := r.Min.Col; col < r.Max.Col; col++ {
+			fn(Loc(col, row))
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package grid
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Text maps are plain-text files in which each character, or glyph, represents
+// a cell. A text map starts with a legend, which maps glyphs to cells, followed
+// by an empty line and the rows of the map; for instance
+//
+//	~ = Water, . = Sand
+//	# = 4
+//
+//	~~~..
+//	~..##
+//
+// Legend entries are separated by commas or newlines. The cell of a glyph is
+// either a tile name, as resolved by the names provided when reading the text
+// map, or a tile identifier. Glyphs may be any characters except whitespace and
+// commas. Lines of the legend starting with "//" are comments; the rows have no
+// comments, so that any glyph may start a row.
+
+// A Legend maps the glyphs of text maps to cells.
+type Legend map[rune]Cell
+
+// OpenText opens the text map specified by path and returns the map it
+// contains, the cells of which have the provided geometry. Tile names of the
+// legend are resolved using names.
+func OpenText(path string, geom Geometry, names map[string]Cell) (m *Map, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err = ReadText(f, geom, names)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return m, nil
+}
+
+// ReadText reads a text map from r and returns the map it contains, the cells
+// of which have the provided geometry. Tile names of the legend are resolved
+// using names. Errors report the line and column of the offending text.
+func ReadText(r io.Reader, geom Geometry, names map[string]Cell) (m *Map, err error) {
+	s := bufio.NewScanner(r)
+	legend := make(Legend)
+	lineNum := 0
+
+	// Parse legend.
+	for s.Scan() {
+		lineNum++
+		line := s.Text()
+		if strings.HasPrefix(line, "//") {
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			if len(legend) == 0 {
+				continue
+			}
+			break
+		}
+		err = parseLegend(line, lineNum, legend, names)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Parse rows.
+	var rows [][]Cell
+	cols := -1
+	// Line number of the first empty line after the rows, if any.
+	emptyLine := 0
+	for s.Scan() {
+		lineNum++
+		line := strings.TrimRightFunc(s.Text(), unicode.IsSpace)
+		if line == "" {
+			// Permit leading and trailing empty lines.
+			if emptyLine == 0 && len(rows) > 0 {
+				emptyLine = lineNum
+			}
+			continue
+		}
+		if emptyLine != 0 {
+			return nil, fmt.Errorf("grid: line %d: empty line between rows", emptyLine)
+		}
+		var row []Cell
+		col := 0
+		for _, glyph := range line {
+			col++
+			cell, ok := legend[glyph]
+			if !ok {
+				return nil, fmt.Errorf("grid: line %d, column %d: unknown glyph %q", lineNum, col, glyph)
+			}
+			row = append(row, cell)
+		}
+		if cols == -1 {
+			cols = len(row)
+		} else if len(row) != cols {
+			return nil, fmt.Errorf("grid: line %d: ragged row of %d cells; expected %d", lineNum, len(row), cols)
+		}
+		rows = append(rows, row)
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("grid: line %d: %v", lineNum+1, err)
+	}
+
+	// Text maps are stored in row-major order whereas the cells of Map are
+	// indexed by column first.
+	if cols == -1 {
+		cols = 0
+	}
+	m = NewMap(cols, len(rows), geom)
+	for row, cells := range rows {
+		for col, cell := range cells {
+			m.Cells[col][row] = cell
+		}
+	}
+	return m, nil
+}
+
+// parseLegend parses the legend entries of line into legend.
+func parseLegend(line string, lineNum int, legend Legend, names map[string]Cell) error {
+	col := 1
+	for _, entry := range strings.Split(line, ",") {
+		// Column of the first character of the trimmed entry.
+		pos := col + len([]rune(entry)) - len([]rune(strings.TrimLeftFunc(entry, unicode.IsSpace)))
+		col += len([]rune(entry)) + 1
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		glyph, size := utf8.DecodeRuneInString(entry)
+		rest := strings.TrimSpace(entry[size:])
+		if !strings.HasPrefix(rest, "=") {
+			return fmt.Errorf("grid: line %d, column %d: invalid legend entry %q; expected \"GLYPH = TILE\"", lineNum, pos, entry)
+		}
+		value := strings.TrimSpace(rest[1:])
+		if _, ok := legend[glyph]; ok {
+			return fmt.Errorf("grid: line %d, column %d: duplicate glyph %q", lineNum, pos, glyph)
+		}
+		cell, ok := names[value]
+		if !ok {
+			id, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("grid: line %d, column %d: unknown tile %q", lineNum, pos, value)
+			}
+			cell = Cell(id)
+		}
+		legend[glyph] = cell
+	}
+	return nil
+}
+
+// WriteText writes the map to w as a text map, using the glyphs of the provided
+// legend. Cells of the legend are written using the tile names of names, if
+// present, and as tile identifiers otherwise. It is an error for a cell of the
+// map to be absent from the legend.
+func WriteText(w io.Writer, m *Map, legend Legend, names map[string]Cell) error {
+	glyphs := make(map[Cell]rune)
+	var keys []rune
+	for glyph, cell := range legend {
+		if unicode.IsSpace(glyph) || glyph == ',' {
+			return fmt.Errorf("grid: invalid glyph %q", glyph)
+		}
+		keys = append(keys, glyph)
+		// Prefer the lowest glyph of cells with several glyphs, for
+		// deterministic output.
+		if prev, ok := glyphs[cell]; !ok || glyph < prev {
+			glyphs[cell] = glyph
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	cellNames := make(map[Cell]string)
+	for name, cell := range names {
+		if prev, ok := cellNames[cell]; !ok || name < prev {
+			cellNames[cell] = name
+		}
+	}
+
+	// Write legend, one entry per line.
+	bw := bufio.NewWriter(w)
+	for _, glyph := range keys {
+		cell := legend[glyph]
+		value, ok := cellNames[cell]
+		if !ok {
+			value = strconv.Itoa(int(cell))
+		}
+		fmt.Fprintf(bw, "%c = %s\n", glyph, value)
+	}
+	bw.WriteString("\n")
+
+	// Write rows.
+	for row := 0; row < m.Rows(); row++ {
+		for col := 0; col < m.Cols(); col++ {
+			cell := m.Cells[col][row]
+			glyph, ok := glyphs[cell]
+			if !ok {
+				return fmt.Errorf("grid: cell %d at (%d, %d) absent from legend", cell, col, row)
+			}
+			bw.WriteRune(glyph)
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
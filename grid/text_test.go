@@ -0,0 +1,78 @@
+package grid
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// tileNames maps the tile names of the test text maps to cells.
+var tileNames = map[string]Cell{
+	"Grass": 1,
+	"Water": 3,
+}
+
+func TestReadText(t *testing.T) {
+	const text = `// Legend.
+" = Grass, ~ = Water
+# = 7
+
+"~#
+~~"
+`
+	m, err := ReadText(strings.NewReader(text), DefaultGeometry(), tileNames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]Cell{{1, 3}, {3, 3}, {7, 1}}
+	if m.Cols() != len(want) || m.Rows() != len(want[0]) {
+		t.Fatalf("dimension mismatch; expected %dx%d, got %dx%d", len(want), len(want[0]), m.Cols(), m.Rows())
+	}
+	for col := range want {
+		for row, cell := range want[col] {
+			if got := m.Cells[col][row]; got != cell {
+				t.Errorf("cell mismatch at (%d, %d); expected %d, got %d", col, row, cell, got)
+			}
+		}
+	}
+}
+
+func TestReadTextError(t *testing.T) {
+	golden := []struct {
+		name string
+		text string
+		err  string
+	}{
+		{name: "unknown glyph", text: ". = Grass\n\n...\n.x.\n", err: `grid: line 4, column 2: unknown glyph 'x'`},
+		{name: "ragged row", text: ". = Grass\n\n...\n..\n", err: "grid: line 4: ragged row of 2 cells; expected 3"},
+		{name: "empty line between rows", text: ". = Grass\n\n...\n\n...\n", err: "grid: line 4: empty line between rows"},
+		{name: "bad legend entry", text: ". = Grass, ~ Water\n\n.\n", err: `grid: line 1, column 12: invalid legend entry "~ Water"; expected "GLYPH = TILE"`},
+		{name: "duplicate glyph", text: ". = Grass\n. = Water\n\n.\n", err: `grid: line 2, column 1: duplicate glyph '.'`},
+		{name: "unknown tile", text: "// Legend.\n. = Grass,  ~ = Lava\n\n.\n", err: `grid: line 2, column 13: unknown tile "Lava"`},
+		{name: "line too long", text: ". = Grass\n\n.\n" + strings.Repeat(".", 70000) + "\n", err: "grid: line 4: bufio.Scanner: token too long"},
+	}
+	for _, g := range golden {
+		_, err := ReadText(strings.NewReader(g.text), DefaultGeometry(), tileNames)
+		if err == nil {
+			t.Errorf("%s: expected error %q, got nil", g.name, g.err)
+			continue
+		}
+		if err.Error() != g.err {
+			t.Errorf("%s: error mismatch; expected %q, got %q", g.name, g.err, err)
+		}
+	}
+}
+
+// errReader is a reader which always fails.
+type errReader struct{}
+
+func (errReader) Read(p []byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestReadTextReadError(t *testing.T) {
+	_, err := ReadText(errReader{}, DefaultGeometry(), tileNames)
+	if err == nil || err.Error() != "grid: line 1: read failed" {
+		t.Errorf("error mismatch; expected %q, got %v", "grid: line 1: read failed", err)
+	}
+}
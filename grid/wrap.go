@@ -0,0 +1,82 @@
+package grid
+
+// A Wrap specifies the axes along which a map wraps around; cells past one edge
+// of a wrapping axis continue at the opposite edge, as on a globe.
+type Wrap uint8
+
+// Wrap axes.
+const (
+	// WrapHorizontal wraps columns around; east to west.
+	WrapHorizontal Wrap = 1 << iota
+	// WrapVertical wraps rows around; north to south.
+	WrapVertical
+	// WrapBoth wraps columns and rows around, forming a torus.
+	WrapBoth = WrapHorizontal | WrapVertical
+)
+
+// Normalize wraps loc around the wrapping axes of a map of the specified number
+// of columns and rows. The boolean return value is false if the normalized
+// location is outside of the map.
+func (w Wrap) Normalize(loc Location, cols, rows int) (Location, bool) {
+	if w&WrapHorizontal != 0 && cols > 0 {
+		loc.Col = mod(loc.Col, cols)
+	}
+	if w&WrapVertical != 0 && rows > 0 {
+		loc.Row = mod(loc.Row, rows)
+	}
+	ok := loc.Col >= 0 && loc.Col < cols && loc.Row >= 0 && loc.Row < rows
+	return loc, ok
+}
+
+// Delta returns the column and row deltas of the shortest displacement from a
+// to b on a map of the specified number of columns and rows, wrapping around
+// the wrapping axes.
+func (w Wrap) Delta(a, b Location, cols, rows int) (dcol, drow int) {
+	dcol = b.Col - a.Col
+	drow = b.Row - a.Row
+	if w&WrapHorizontal != 0 && cols > 0 {
+		dcol = shortest(dcol, cols)
+	}
+	if w&WrapVertical != 0 && rows > 0 {
+		drow = shortest(drow, rows)
+	}
+	return dcol, drow
+}
+
+// Normalize wraps loc around the wrapping axes of the map. The boolean return
+// value is false if the normalized location is outside of the map.
+func (m *Map) Normalize(loc Location) (Location, bool) {
+	return m.Wrap.Normalize(loc, m.Cols(), m.Rows())
+}
+
+// Offset returns the location offset from loc by the provided column and row
+// deltas, wrapping around the wrapping axes of the map. The boolean return
+// value is false if the offset location is outside of the map.
+func (m *Map) Offset(loc Location, dcol, drow int) (Location, bool) {
+	return m.Normalize(Loc(loc.Col+dcol, loc.Row+drow))
+}
+
+// Delta returns the column and row deltas of the shortest displacement from a
+// to b, wrapping around the wrapping axes of the map.
+func (m *Map) Delta(a, b Location) (dcol, drow int) {
+	return m.Wrap.Delta(a, b, m.Cols(), m.Rows())
+}
+
+// mod returns a modulo n in the range [0, n), for n > 0.
+func mod(a, n int) int {
+	a %= n
+	if a < 0 {
+		a += n
+	}
+	return a
+}
+
+// shortest returns the delta d wrapped around an axis of length n, in the range
+// [-n/2, n/2].
+func shortest(d, n int) int {
+	d = mod(d, n)
+	if d > n/2 {
+		d -= n
+	}
+	return d
+}
@@ -0,0 +1,92 @@
+// Package hex implements hexagonal grids in pointy-top and flat-top layouts,
+// using offset, axial and cube coordinates.
+//
+// The cells of hexagonal maps are stored in grid.Map values, the grid
+// locations of which are offset coordinates; every other row (pointy-top) or
+// column (flat-top) is shifted by half a cell.
+//
+// Reference: https://www.redblobgames.com/grids/hexagons/
+package hex
+
+import (
+	"math"
+)
+
+// An Axial specifies the axial coordinates of a hexagonal cell.
+type Axial struct {
+	Q, R int
+}
+
+// A Cube specifies the cube coordinates of a hexagonal cell. The coordinates
+// of valid cube locations always sum up to zero.
+type Cube struct {
+	Q, R, S int
+}
+
+// Cube returns the cube coordinates of a.
+func (a Axial) Cube() Cube {
+	return Cube{Q: a.Q, R: a.R, S: -a.Q - a.R}
+}
+
+// Axial returns the axial coordinates of c.
+func (c Cube) Axial() Axial {
+	return Axial{Q: c.Q, R: c.R}
+}
+
+// Add returns the vector a+b.
+func (a Axial) Add(b Axial) Axial {
+	return Axial{Q: a.Q + b.Q, R: a.R + b.R}
+}
+
+// Sub returns the vector a-b.
+func (a Axial) Sub(b Axial) Axial {
+	return Axial{Q: a.Q - b.Q, R: a.R - b.R}
+}
+
+// Directions holds the axial deltas to the six neighbours of a cell.
+var Directions = [6]Axial{
+	{Q: 1, R: 0},
+	{Q: 1, R: -1},
+	{Q: 0, R: -1},
+	{Q: -1, R: 0},
+	{Q: -1, R: 1},
+	{Q: 0, R: 1},
+}
+
+// Neighbors returns the six neighbours of a.
+func (a Axial) Neighbors() (neighbors [6]Axial) {
+	for i, dir := range Directions {
+		neighbors[i] = a.Add(dir)
+	}
+	return neighbors
+}
+
+// Distance returns the number of steps between a and b.
+func Distance(a, b Axial) int {
+	d := a.Sub(b).Cube()
+	return (abs(d.Q) + abs(d.R) + abs(d.S)) / 2
+}
+
+// round returns the cube coordinates of the cell containing the fractional
+// cube coordinates (q, r, s).
+func round(q, r, s float64) Cube {
+	rq, rr, rs := math.Round(q), math.Round(r), math.Round(s)
+	dq, dr, ds := math.Abs(rq-q), math.Abs(rr-r), math.Abs(rs-s)
+	switch {
+	case dq > dr && dq > ds:
+		rq = -rr - rs
+	case dr > ds:
+		rr = -rq - rs
+	default:
+		rs = -rq - rr
+	}
+	return Cube{Q: int(rq), R: int(rr), S: int(rs)}
+}
+
+// abs returns the absolute value of x.
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
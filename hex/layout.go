@@ -0,0 +1,215 @@
+package hex
+
+import (
+	"image"
+
+	"github.com/mewmew/pgg/grid"
+)
+
+// An Orientation specifies the orientation of hexagonal cells.
+type Orientation int
+
+// Orientations.
+const (
+	// PointyTop hexagons have a vertex at the top; every other row is shifted
+	// horizontally by half a cell.
+	PointyTop Orientation = iota
+	// FlatTop hexagons have an edge at the top; every other column is shifted
+	// vertically by half a cell.
+	FlatTop
+)
+
+// A Stagger specifies which rows (pointy-top) or columns (flat-top) of a
+// hexagonal map are shifted by half a cell.
+type Stagger int
+
+// Staggers.
+const (
+	// Odd rows or columns are shifted.
+	Odd Stagger = iota
+	// Even rows or columns are shifted.
+	Even
+)
+
+// A Layout specifies the layout of the cells of a hexagonal map. It implements
+// grid.Projection.
+type Layout struct {
+	// Orientation of the hexagonal cells.
+	Orientation Orientation
+	// Shifted rows or columns.
+	Stagger Stagger
+	// Cell geometry, which specifies the bounds of the tile images of the
+	// hexagonal cells.
+	grid.Geometry
+}
+
+// step returns the pixel distance between the columns and rows of the layout.
+// Adjacent rows (pointy-top) or columns (flat-top) overlap by a quarter cell.
+func (l Layout) step() image.Point {
+	if l.Orientation == FlatTop {
+		return image.Pt(3*l.CellWidth/4, l.CellHeight)
+	}
+	return image.Pt(l.CellWidth, 3*l.CellHeight/4)
+}
+
+// shifted returns true if the row (pointy-top) or column (flat-top) at loc is
+// shifted by half a cell.
+func (l Layout) shifted(loc grid.Location) bool {
+	i := loc.Row
+	if l.Orientation == FlatTop {
+		i = loc.Col
+	}
+	odd := i&1 == 1
+	return odd == (l.Stagger == Odd)
+}
+
+// Axial returns the axial coordinates of the cell at loc.
+func (l Layout) Axial(loc grid.Location) Axial {
+	if l.Orientation == FlatTop {
+		r := loc.Row - (loc.Col-loc.Col&1)/2
+		if l.Stagger == Even {
+			r = loc.Row - (loc.Col+loc.Col&1)/2
+		}
+		return Axial{Q: loc.Col, R: r}
+	}
+	q := loc.Col - (loc.Row-loc.Row&1)/2
+	if l.Stagger == Even {
+		q = loc.Col - (loc.Row+loc.Row&1)/2
+	}
+	return Axial{Q: q, R: loc.Row}
+}
+
+// Loc returns the grid location of the cell at the axial coordinates a.
+func (l Layout) Loc(a Axial) grid.Location {
+	if l.Orientation == FlatTop {
+		row := a.R + (a.Q-a.Q&1)/2
+		if l.Stagger == Even {
+			row = a.R + (a.Q+a.Q&1)/2
+		}
+		return grid.Loc(a.Q, row)
+	}
+	col := a.Q + (a.R-a.R&1)/2
+	if l.Stagger == Even {
+		col = a.Q + (a.R+a.R&1)/2
+	}
+	return grid.Loc(col, a.R)
+}
+
+// Neighbors returns the grid locations of the six neighbours of the cell at
+// loc.
+func (l Layout) Neighbors(loc grid.Location) (neighbors [6]grid.Location) {
+	for i, a := range l.Axial(loc).Neighbors() {
+		neighbors[i] = l.Loc(a)
+	}
+	return neighbors
+}
+
+// Distance returns the number of steps between the cells at a and b.
+func (l Layout) Distance(a, b grid.Location) int {
+	return Distance(l.Axial(a), l.Axial(b))
+}
+
+// Pixel returns the top left world pixel of the bounds of the cell at loc.
+func (l Layout) Pixel(loc grid.Location) image.Point {
+	step := l.step()
+	p := image.Pt(loc.Col*step.X, loc.Row*step.Y)
+	if l.shifted(loc) {
+		if l.Orientation == FlatTop {
+			p.Y += l.CellHeight / 2
+		} else {
+			p.X += l.CellWidth / 2
+		}
+	}
+	return p
+}
+
+// CellBounds returns the world pixel bounds of the cell at loc.
+func (l Layout) CellBounds(loc grid.Location) image.Rectangle {
+	return image.Rectangle{Max: l.Size(1, 1)}.Add(l.Pixel(loc))
+}
+
+// Pick returns the location of the hexagonal cell containing the world pixel
+// p.
+func (l Layout) Pick(p image.Point) grid.Location {
+	// Locate p relative to the center of the cell at axial (0, 0).
+	origin := l.Pixel(grid.Loc(0, 0)).Add(image.Pt(l.CellWidth/2, l.CellHeight/2))
+	step := l.step()
+	x := float64(p.X - origin.X)
+	y := float64(p.Y - origin.Y)
+	var q, r float64
+	if l.Orientation == FlatTop {
+		q = x / float64(step.X)
+		r = y/float64(step.Y) - q/2
+	} else {
+		r = y / float64(step.Y)
+		q = x/float64(step.X) - r/2
+	}
+	return l.Loc(round(q, r, -q-r).Axial())
+}
+
+// Bounds returns the world pixel bounds of a map of the specified number of
+// columns and rows.
+func (l Layout) Bounds(cols, rows int) image.Rectangle {
+	if cols < 1 || rows < 1 {
+		return image.Rectangle{}
+	}
+	step := l.step()
+	w := (cols-1)*step.X + l.CellWidth
+	h := (rows-1)*step.Y + l.CellHeight
+	if l.Orientation == FlatTop && cols > 1 {
+		h += l.CellHeight / 2
+	}
+	if l.Orientation == PointyTop && rows > 1 {
+		w += l.CellWidth / 2
+	}
+	return image.Rect(0, 0, w, h)
+}
+
+// Range returns the inclusive range of cells whose bounds may intersect the
+// world pixel rectangle r.
+func (l Layout) Range(r image.Rectangle) (min, max grid.Location) {
+	step := l.step()
+	// Account for the overlap of cell bounds and the shift of every other row
+	// or column.
+	pad := image.Pt(l.CellWidth-step.X, l.CellHeight-step.Y)
+	if l.Orientation == FlatTop {
+		pad.Y += l.CellHeight / 2
+	} else {
+		pad.X += l.CellWidth / 2
+	}
+	min = grid.Loc(floorDiv(r.Min.X-pad.X, step.X), floorDiv(r.Min.Y-pad.Y, step.Y))
+	max = grid.Loc(floorDiv(r.Max.X-1, step.X), floorDiv(r.Max.Y-1, step.Y))
+	return min, max
+}
+
+// Order invokes fn for each cell in the inclusive range between min and max,
+// back to front. Rows are visited top to bottom; within a row of a flat-top
+// layout, the columns which are not shifted are visited before the columns
+// which are shifted down.
+func (l Layout) Order(min, max grid.Location, fn func(loc grid.Location)) {
+	for row := min.Row; row <= max.Row; row++ {
+		if l.Orientation == PointyTop {
+			for col := min.Col; col <= max.Col; col++ {
+				fn(grid.Loc(col, row))
+			}
+			continue
+		}
+		for _, shifted := range []bool{false, true} {
+			for col := min.Col; col <= max.Col; col++ {
+				loc := grid.Loc(col, row)
+				if l.shifted(loc) == shifted {
+					fn(loc)
+				}
+			}
+		}
+	}
+}
+
+// floorDiv returns a/b rounded towards negative infinity, for b > 0.
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
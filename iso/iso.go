@@ -0,0 +1,129 @@
+// Package iso implements isometric projections of grid maps, in diamond and
+// staggered layouts.
+//
+// The cell geometry of an isometric projection specifies the footprint of its
+// cells; the bounds of the diamond shaped top face of a flat tile. Tile images
+// may be taller than their footprint, for instance to depict walls and
+// buildings, in which case they extend upwards from the bottom of the
+// footprint.
+//
+// Reference: http://doc.mapeditor.org/manual/projects/#isometric-maps
+package iso
+
+import (
+	"image"
+
+	"github.com/mewmew/pgg/grid"
+)
+
+// A Diamond is an isometric projection in which the map forms a diamond, with
+// the cell at column 0 and row 0 at the top. Columns run towards the bottom
+// right and rows towards the bottom left. It implements grid.Projection.
+type Diamond struct {
+	// Cell footprint geometry.
+	grid.Geometry
+	// Height of the tile images; or zero if equal to the cell height.
+	TileHeight int
+	// Number of rows of the map, which determines the horizontal position of
+	// the top cell within the world.
+	Rows int
+}
+
+// extra returns the number of pixels by which tile images extend above their
+// footprint.
+func (d Diamond) extra() int {
+	return extra(d.Geometry, d.TileHeight)
+}
+
+// Pixel returns the top left world pixel of the tile image of the cell at loc.
+func (d Diamond) Pixel(loc grid.Location) image.Point {
+	x := (loc.Col - loc.Row + d.Rows - 1) * d.CellWidth / 2
+	y := (loc.Col + loc.Row) * d.CellHeight / 2
+	return image.Pt(x, y)
+}
+
+// CellBounds returns the world pixel bounds of the tile image of the cell at
+// loc.
+func (d Diamond) CellBounds(loc grid.Location) image.Rectangle {
+	return image.Rect(0, 0, d.CellWidth, d.CellHeight+d.extra()).Add(d.Pixel(loc))
+}
+
+// Pick returns the location of the cell whose footprint contains the world
+// pixel p.
+func (d Diamond) Pick(p image.Point) grid.Location {
+	// Locate p relative to the top corner of the footprint of the cell at
+	// column 0 and row 0.
+	x := p.X - d.Rows*d.CellWidth/2
+	y := p.Y - d.extra()
+	return pick(d.Geometry, x, y)
+}
+
+// Bounds returns the world pixel bounds of a map of the specified number of
+// columns and rows; rows is expected to be equal to the Rows of the
+// projection.
+func (d Diamond) Bounds(cols, rows int) image.Rectangle {
+	if cols < 1 || rows < 1 {
+		return image.Rectangle{}
+	}
+	w := (cols + rows) * d.CellWidth / 2
+	h := (cols+rows)*d.CellHeight/2 + d.extra()
+	return image.Rect(0, 0, w, h)
+}
+
+// Range returns the inclusive range of cells whose tile images may intersect
+// the world pixel rectangle r. As the range is rectangular in columns and rows
+// it also includes cells outside of r; use CellBounds to cull them.
+func (d Diamond) Range(r image.Rectangle) (min, max grid.Location) {
+	// Cells are located by their horizontal index u = col-row and vertical
+	// index v = col+row.
+	w, h := d.CellWidth, d.CellHeight
+	uMin := floorDiv(2*(r.Min.X-w), w) - d.Rows + 1
+	uMax := floorDiv(2*(r.Max.X-1), w) - d.Rows + 1
+	vMin := floorDiv(2*(r.Min.Y-h-d.extra()), h)
+	vMax := floorDiv(2*(r.Max.Y-1), h)
+	min = grid.Loc(floorDiv(uMin+vMin, 2), floorDiv(vMin-uMax, 2))
+	max = grid.Loc(floorDiv(uMax+vMax+1, 2), floorDiv(vMax-uMin+1, 2))
+	return min, max
+}
+
+// Order invokes fn for each cell in the inclusive range between min and max,
+// back to front; diagonal by diagonal, from the top of the map to the bottom.
+func (d Diamond) Order(min, max grid.Location, fn func(loc grid.Location)) {
+	for v := min.Col + min.Row; v <= max.Col+max.Row; v++ {
+		for col := max.Col; col >= min.Col; col-- {
+			row := v - col
+			if row < min.Row || row > max.Row {
+				continue
+			}
+			fn(grid.Loc(col, row))
+		}
+	}
+}
+
+// extra returns the number of pixels by which tile images of the specified
+// height extend above the cell footprint.
+func extra(geom grid.Geometry, tileHeight int) int {
+	if tileHeight <= geom.CellHeight {
+		return 0
+	}
+	return tileHeight - geom.CellHeight
+}
+
+// pick returns the location of the cell of a diamond projection whose
+// footprint contains the point (x, y), relative to the top corner of the
+// footprint of the cell at column 0 and row 0.
+func pick(geom grid.Geometry, x, y int) grid.Location {
+	w, h := geom.CellWidth, geom.CellHeight
+	col := floorDiv(y*w+x*h, w*h)
+	row := floorDiv(y*w-x*h, w*h)
+	return grid.Loc(col, row)
+}
+
+// floorDiv returns a/b rounded towards negative infinity, for b > 0.
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,116 @@
+package iso
+
+import (
+	"image"
+
+	"github.com/mewmew/pgg/grid"
+)
+
+// A Stagger specifies which rows of a staggered isometric map are shifted by
+// half a cell.
+type Stagger int
+
+// Staggers.
+const (
+	// Odd rows are shifted.
+	Odd Stagger = iota
+	// Even rows are shifted.
+	Even
+)
+
+// A Staggered is an isometric projection in which the map forms a rectangle;
+// rows are offset vertically by half a cell and every other row is shifted
+// horizontally by half a cell. It implements grid.Projection.
+type Staggered struct {
+	// Cell footprint geometry.
+	grid.Geometry
+	// Height of the tile images; or zero if equal to the cell height.
+	TileHeight int
+	// Shifted rows.
+	Stagger Stagger
+}
+
+// extra returns the number of pixels by which tile images extend above their
+// footprint.
+func (s Staggered) extra() int {
+	return extra(s.Geometry, s.TileHeight)
+}
+
+// shifted returns true if the row at loc is shifted by half a cell.
+func (s Staggered) shifted(row int) bool {
+	odd := row&1 == 1
+	return odd == (s.Stagger == Odd)
+}
+
+// Pixel returns the top left world pixel of the tile image of the cell at loc.
+func (s Staggered) Pixel(loc grid.Location) image.Point {
+	x := loc.Col * s.CellWidth
+	if s.shifted(loc.Row) {
+		x += s.CellWidth / 2
+	}
+	y := loc.Row * s.CellHeight / 2
+	return image.Pt(x, y)
+}
+
+// CellBounds returns the world pixel bounds of the tile image of the cell at
+// loc.
+func (s Staggered) CellBounds(loc grid.Location) image.Rectangle {
+	return image.Rect(0, 0, s.CellWidth, s.CellHeight+s.extra()).Add(s.Pixel(loc))
+}
+
+// Pick returns the location of the cell whose footprint contains the world
+// pixel p.
+func (s Staggered) Pick(p image.Point) grid.Location {
+	// The footprints of a staggered map coincide with those of a diamond map
+	// whose top corner is located at the top corner of the cell at column 0
+	// and row 0. Cells of the diamond map are located by their horizontal
+	// index u = col-row and vertical index v = col+row, which correspond to
+	// the horizontal index u = 2*col (+1 if shifted, -1 if row 0 is shifted)
+	// and the row v of the staggered map.
+	x := p.X - s.Pixel(grid.Loc(0, 0)).X - s.CellWidth/2
+	d := pick(s.Geometry, x, p.Y-s.extra())
+	u := d.Col - d.Row
+	row := d.Col + d.Row
+	if s.shifted(row) {
+		u--
+	}
+	if s.shifted(0) {
+		u++
+	}
+	return grid.Loc(floorDiv(u, 2), row)
+}
+
+// Bounds returns the world pixel bounds of a map of the specified number of
+// columns and rows.
+func (s Staggered) Bounds(cols, rows int) image.Rectangle {
+	if cols < 1 || rows < 1 {
+		return image.Rectangle{}
+	}
+	w := cols * s.CellWidth
+	if rows > 1 || s.shifted(0) {
+		w += s.CellWidth / 2
+	}
+	h := (rows+1)*s.CellHeight/2 + s.extra()
+	return image.Rect(0, 0, w, h)
+}
+
+// Range returns the inclusive range of cells whose tile images may intersect
+// the world pixel rectangle r.
+func (s Staggered) Range(r image.Rectangle) (min, max grid.Location) {
+	w, h := s.CellWidth, s.CellHeight
+	min.Col = floorDiv(r.Min.X-w-w/2, w) + 1
+	min.Row = floorDiv(2*(r.Min.Y-h-s.extra()), h) + 1
+	max.Col = floorDiv(r.Max.X-1, w)
+	max.Row = floorDiv(2*(r.Max.Y-1), h)
+	return min, max
+}
+
+// Order invokes fn for each cell in the inclusive range between min and max,
+// back to front; row by row, from the top of the map to the bottom.
+func (s Staggered) Order(min, max grid.Location, fn func(loc grid.Location)) {
+	for row := min.Row; row <= max.Row; row++ {
+		for col := min.Col; col <= max.Col; col++ {
+			fn(grid.Loc(col, row))
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package path
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/mewmew/pgg/grid"
+)
+
+// A Field is a Dijkstra map which records the cost of the cheapest path from
+// every cell of a map to the nearest of one or more goals. Any number of agents
+// heading to the same goals may follow the field downhill.
+type Field struct {
+	// Path finder of the field.
+	f *Finder
+	// Cost of the cheapest path to the nearest goal, indexed by column and row.
+	dist [][]float64
+	// Neighbour to step to along the cheapest path to the nearest goal,
+	// indexed by column and row; goals step to themselves.
+	next [][]grid.Location
+}
+
+// Field computes the Dijkstra map of the cheapest paths from every cell of the
+// map to the nearest of the provided goals.
+func (f *Finder) Field(goals ...grid.Location) *Field {
+	field := &Field{
+		f:    f,
+		dist: newGrid(f.Map.Cols(), f.Map.Rows(), math.Inf(1)),
+		next: make([][]grid.Location, f.Map.Cols()),
+	}
+	for col := range field.next {
+		field.next[col] = make([]grid.Location, f.Map.Rows())
+	}
+	q := &queue{}
+	for _, goal := range goals {
+		goal, _ = f.Map.Normalize(goal)
+		if f.passable(goal) {
+			field.dist[goal.Col][goal.Row] = 0
+			field.next[goal.Col][goal.Row] = goal
+			heap.Push(q, item{loc: goal})
+		}
+	}
+	for q.Len() > 0 {
+		it := heap.Pop(q).(item)
+		cur := it.loc
+		if it.prio > field.dist[cur.Col][cur.Row] {
+			// Stale queue item.
+			continue
+		}
+		// Moves are reversible, so the cost of stepping from next to cur is the
+		// cost of entering cur.
+		f.neighbors(cur, func(next grid.Location, factor float64) {
+			d := field.dist[cur.Col][cur.Row] + factor*f.cost(cur)
+			if d < field.dist[next.Col][next.Row] {
+				field.dist[next.Col][next.Row] = d
+				field.next[next.Col][next.Row] = cur
+				heap.Push(q, item{loc: next, prio: d})
+			}
+		})
+	}
+	return field
+}
+
+// Cost returns the cost of the cheapest path from loc to the nearest goal. The
+// cost is infinite if no goal is reachable from loc.
+func (field *Field) Cost(loc grid.Location) float64 {
+	loc, ok := field.f.Map.Normalize(loc)
+	if !ok {
+		return math.Inf(1)
+	}
+	return field.dist[loc.Col][loc.Row]
+}
+
+// Next returns the neighbour of loc to step to in order to follow the cheapest
+// path towards the nearest goal. The boolean return value is false if loc is a
+// goal or if no goal is reachable from loc. Following Next from any location
+// always reaches a goal, even across cells with a cost of zero.
+func (field *Field) Next(loc grid.Location) (next grid.Location, ok bool) {
+	loc, ok = field.f.Map.Normalize(loc)
+	if !ok || math.IsInf(field.dist[loc.Col][loc.Row], 1) {
+		return grid.Location{}, false
+	}
+	next = field.next[loc.Col][loc.Row]
+	if next == loc {
+		// Goal.
+		return grid.Location{}, false
+	}
+	return next, true
+}
+
+// Path returns the cheapest path from start to the nearest goal, including
+// both start and the goal. The boolean return value is false if no goal is
+// reachable from start.
+func (field *Field) Path(start grid.Location) (path []grid.Location, ok bool) {
+	if math.IsInf(field.Cost(start), 1) {
+		return nil, false
+	}
+	path = []grid.Location{start}
+	for loc := start; ; {
+		next, ok := field.Next(loc)
+		if !ok {
+			break
+		}
+		path = append(path, next)
+		loc = next
+	}
+	return path, true
+}
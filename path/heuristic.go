@@ -0,0 +1,47 @@
+package path
+
+import (
+	"math"
+
+	"github.com/mewmew/pgg/grid"
+)
+
+// A Heuristic estimates the cost of the cheapest path between two locations.
+// To find the cheapest path, a heuristic must never overestimate the cost.
+type Heuristic func(a, b grid.Location) float64
+
+// Manhattan returns the Manhattan distance between a and b, which suits
+// 4-connectivity.
+func Manhattan(a, b grid.Location) float64 {
+	return float64(a.Manhattan(b))
+}
+
+// Chebyshev returns the Chebyshev distance between a and b, which suits
+// 8-connectivity with diagonal moves as cheap as orthogonal moves.
+func Chebyshev(a, b grid.Location) float64 {
+	return float64(a.Chebyshev(b))
+}
+
+// Octile returns the octile distance between a and b, which suits
+// 8-connectivity with diagonal moves costing the square root of two.
+func Octile(a, b grid.Location) float64 {
+	dx, dy := delta(a, b)
+	return math.Max(dx, dy) + (math.Sqrt2-1)*math.Min(dx, dy)
+}
+
+// Euclidean returns the straight-line distance between a and b.
+func Euclidean(a, b grid.Location) float64 {
+	return a.Euclidean(b)
+}
+
+// Zero always returns 0, which turns A* into Dijkstra's algorithm.
+func Zero(a, b grid.Location) float64 {
+	return 0
+}
+
+// delta returns the absolute column and row differences between a and b.
+func delta(a, b grid.Location) (dx, dy float64) {
+	dx = math.Abs(float64(a.Col - b.Col))
+	dy = math.Abs(float64(a.Row - b.Row))
+	return dx, dy
+}
@@ -0,0 +1,224 @@
+// Package path finds paths between the grid locations of maps.
+package path
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/mewmew/pgg/grid"
+	"github.com/mewmew/pgg/tileset"
+)
+
+// A Connectivity specifies the number of neighbours reachable from a cell in a
+// single step.
+type Connectivity int
+
+// Connectivities.
+const (
+	// Four allows moves to the edge neighbours of a cell.
+	Four Connectivity = 4
+	// Eight allows moves to the edge and corner neighbours of a cell.
+	Eight Connectivity = 8
+)
+
+// A CornerRule specifies whether diagonal moves may cut the corners of
+// impassable cells.
+type CornerRule int
+
+// Corner rules.
+const (
+	// CutCorners allows diagonal moves past impassable cells.
+	CutCorners CornerRule = iota
+	// NoCutCorners disallows diagonal moves past an impassable cell.
+	NoCutCorners
+	// NoSqueeze disallows diagonal moves between two impassable cells, but
+	// allows diagonal moves past a single impassable cell.
+	NoSqueeze
+)
+
+// Costs maps tile identifiers to the cost of entering cells of the given tile.
+// Tile identifiers absent from the mapping have a cost of 1. Cells with a
+// negative or infinite cost are impassable. Costs of passable cells must be
+// greater than zero, and at least 1 for the default heuristics to find the
+// cheapest paths; fields nonetheless reach their goals across cells with a
+// cost of zero.
+type Costs map[tileset.TileID]float64
+
+// Cost returns the cost of entering cells of the tile specified by id. Flipped
+// and rotated tiles have the cost of their unflipped tile.
+func (costs Costs) Cost(id tileset.TileID) float64 {
+	if cost, ok := costs[id.Base()]; ok {
+		return cost
+	}
+	return 1
+}
+
+// Impassable returns the cost of impassable cells.
+func Impassable() float64 {
+	return math.Inf(1)
+}
+
+// A Finder finds paths through the cells of a map.
+type Finder struct {
+	// Map of the paths.
+	Map *grid.Map
+	// Movement costs of the tiles of the map.
+	Costs Costs
+	// Neighbours reachable in a single step; defaults to Four.
+	Connectivity Connectivity
+	// Specifies whether diagonal moves may cut corners.
+	Corners CornerRule
+	// Heuristic used to estimate the remaining cost of A* searches; defaults to
+	// Manhattan for 4-connectivity and Octile for 8-connectivity.
+	Heuristic Heuristic
+}
+
+// NewFinder returns a new path finder for the cells of m, using the provided
+// movement costs.
+func NewFinder(m *grid.Map, costs Costs) (f *Finder) {
+	f = &Finder{
+		Map:          m,
+		Costs:        costs,
+		Connectivity: Four,
+	}
+	return f
+}
+
+// Find searches for the cheapest path from start to goal using A*. The path
+// includes both start and goal, and the cost is the sum of the costs of
+// entering each cell of the path after start. The boolean return value is
+// false if goal is unreachable from start. On wrapping maps, start and goal are
+// normalized and the path may cross the edges of the map.
+func (f *Finder) Find(start, goal grid.Location) (path []grid.Location, cost float64, ok bool) {
+	start, ok = f.Map.Normalize(start)
+	if !ok || !f.passable(goal) {
+		return nil, 0, false
+	}
+	goal, _ = f.Map.Normalize(goal)
+	h := f.estimate(goal)
+	cols, rows := f.Map.Cols(), f.Map.Rows()
+	dist := newGrid(cols, rows, math.Inf(1))
+	prev := make(map[grid.Location]grid.Location)
+	closed := make(map[grid.Location]bool)
+	dist[start.Col][start.Row] = 0
+	q := &queue{{loc: start, prio: h(start)}}
+	for q.Len() > 0 {
+		cur := heap.Pop(q).(item).loc
+		if cur == goal {
+			return walk(prev, start, goal), dist[goal.Col][goal.Row], true
+		}
+		if closed[cur] {
+			continue
+		}
+		closed[cur] = true
+		f.neighbors(cur, func(next grid.Location, factor float64) {
+			d := dist[cur.Col][cur.Row] + factor*f.cost(next)
+			if d < dist[next.Col][next.Row] {
+				dist[next.Col][next.Row] = d
+				prev[next] = cur
+				heap.Push(q, item{loc: next, prio: d + h(next)})
+			}
+		})
+	}
+	return nil, 0, false
+}
+
+// walk returns the path from start to goal recorded by prev.
+func walk(prev map[grid.Location]grid.Location, start, goal grid.Location) []grid.Location {
+	path := []grid.Location{goal}
+	for loc := goal; loc != start; {
+		loc = prev[loc]
+		path = append(path, loc)
+	}
+	// Reverse path.
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// heuristic returns the heuristic of the finder.
+func (f *Finder) heuristic() Heuristic {
+	if f.Heuristic != nil {
+		return f.Heuristic
+	}
+	if f.Connectivity == Eight {
+		return Octile
+	}
+	return Manhattan
+}
+
+// estimate returns a function which estimates the remaining cost from a
+// location to goal using the heuristic of the finder. On wrapping maps, the
+// estimate is towards the nearest wrapped copy of goal.
+func (f *Finder) estimate(goal grid.Location) func(loc grid.Location) float64 {
+	h := f.heuristic()
+	return func(loc grid.Location) float64 {
+		dcol, drow := f.Map.Delta(loc, goal)
+		return h(loc, loc.Add(grid.Loc(dcol, drow)))
+	}
+}
+
+// neighbors invokes fn for each passable neighbour of loc reachable in a
+// single step, with the factor by which the cost of entering the neighbour is
+// multiplied; the square root of two for diagonal moves and 1 otherwise.
+// Neighbours wrap around the wrapping axes of the map.
+func (f *Finder) neighbors(loc grid.Location, fn func(next grid.Location, factor float64)) {
+	n := 4
+	if f.Connectivity == Eight {
+		n = 8
+	}
+	for i, delta := range grid.Directions[:n] {
+		next, ok := f.Map.Offset(loc, delta.Col, delta.Row)
+		if !ok || !f.passable(next) {
+			continue
+		}
+		factor := 1.0
+		if i >= 4 {
+			// Diagonal move.
+			a := f.passable(grid.Loc(loc.Col+delta.Col, loc.Row))
+			b := f.passable(grid.Loc(loc.Col, loc.Row+delta.Row))
+			switch f.Corners {
+			case NoCutCorners:
+				if !a || !b {
+					continue
+				}
+			case NoSqueeze:
+				if !a && !b {
+					continue
+				}
+			}
+			factor = math.Sqrt2
+		}
+		fn(next, factor)
+	}
+}
+
+// cost returns the cost of entering the cell at the normalized location loc.
+func (f *Finder) cost(loc grid.Location) float64 {
+	return f.Costs.Cost(f.Map.Cells[loc.Col][loc.Row])
+}
+
+// passable returns true if the cell at loc is located within the map, after
+// wrapping around the wrapping axes of the map, and is passable.
+func (f *Finder) passable(loc grid.Location) bool {
+	loc, ok := f.Map.Normalize(loc)
+	if !ok {
+		return false
+	}
+	cost := f.cost(loc)
+	return cost >= 0 && !math.IsInf(cost, 1)
+}
+
+// newGrid returns a new two-dimensional array of the specified number of
+// columns and rows, with every element set to v.
+func newGrid(cols, rows int, v float64) [][]float64 {
+	g := make([][]float64, cols)
+	for col := range g {
+		g[col] = make([]float64, rows)
+		for row := range g[col] {
+			g[col][row] = v
+		}
+	}
+	return g
+}
@@ -0,0 +1,42 @@
+package path
+
+import (
+	"testing"
+
+	"github.com/mewmew/pgg/grid"
+	"github.com/mewmew/pgg/tileset"
+)
+
+func TestCost(t *testing.T) {
+	const wall tileset.TileID = 2
+	costs := Costs{wall: Impassable(), 3: 5}
+	golden := []struct {
+		id   tileset.TileID
+		want float64
+	}{
+		{id: 1, want: 1},
+		{id: 3, want: 5},
+		{id: wall, want: Impassable()},
+		{id: wall | tileset.FlipHorizontal, want: Impassable()},
+		{id: wall | tileset.FlipVertical | tileset.FlipDiagonal, want: Impassable()},
+		{id: 3 | tileset.FlipDiagonal, want: 5},
+	}
+	for _, g := range golden {
+		if got := costs.Cost(g.id); got != g.want {
+			t.Errorf("cost mismatch of tile %#x; expected %v, got %v", g.id, g.want, got)
+		}
+	}
+}
+
+func TestFindFlippedWall(t *testing.T) {
+	const wall tileset.TileID = 2
+	// A 3x3 map with a wall of flipped tiles across the middle column.
+	m := grid.NewMap(3, 3, grid.DefaultGeometry())
+	for row := 0; row < 3; row++ {
+		m.Cells[1][row] = wall | tileset.FlipHorizontal
+	}
+	f := NewFinder(m, Costs{wall: Impassable()})
+	if path, _, ok := f.Find(grid.Loc(0, 1), grid.Loc(2, 1)); ok {
+		t.Errorf("expected no path through flipped wall, got %v", path)
+	}
+}
@@ -0,0 +1,32 @@
+package path
+
+import (
+	"github.com/mewmew/pgg/grid"
+)
+
+// An item is a location queued with a given priority.
+type item struct {
+	// Queued location.
+	loc grid.Location
+	// Priority of the location; lower values are dequeued first.
+	prio float64
+}
+
+// A queue is a priority queue of locations, which implements heap.Interface.
+type queue []item
+
+func (q queue) Len() int           { return len(q) }
+func (q queue) Less(i, j int) bool { return q[i].prio < q[j].prio }
+func (q queue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *queue) Push(x interface{}) {
+	*q = append(*q, x.(item))
+}
+
+func (q *queue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	x := old[n-1]
+	*q = old[:n-1]
+	return x
+}
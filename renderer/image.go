@@ -0,0 +1,110 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/mewmew/pgg/tileset"
+)
+
+// An Image is a renderer which draws tile images onto a destination image using
+// image/draw. It requires no graphics hardware and is therefore suitable for
+// headless rendering.
+type Image struct {
+	// Destination image.
+	Dst draw.Image
+	// Tile sets of the drawn tile images.
+	TileSets *tileset.Registry
+	// Opacity mask of drawn tile images; or nil if opaque.
+	mask image.Image
+	// Scale factor of drawn tile images.
+	scale float64
+	// Scaled tile images, indexed by the unscaled tile images; reset when the
+	// scale factor changes.
+	scaled map[image.Image]image.Image
+}
+
+// NewImage returns a new renderer which draws tile images from the provided tile
+// sets onto dst.
+func NewImage(dst draw.Image, tilesets *tileset.Registry) (r *Image) {
+	r = &Image{
+		Dst:      dst,
+		TileSets: tilesets,
+		scale:    1,
+	}
+	return r
+}
+
+// DrawTile draws the tile image specified by the global tile identifier id at
+// the destination point dp. Tile identifiers not contained within any tile set
+// are ignored.
+func (r *Image) DrawTile(id tileset.TileID, dp image.Point) {
+	tile := r.TileSets.Tile(id)
+	if tile == nil {
+		return
+	}
+	if r.scale != 1 {
+		tile = r.scaleTile(tile)
+	}
+	bounds := tile.Bounds()
+	dr := image.Rectangle{Min: dp, Max: dp.Add(bounds.Size())}
+	draw.DrawMask(r.Dst, dr, tile, bounds.Min, r.mask, image.ZP, draw.Over)
+}
+
+// SetOpacity sets the opacity, in the range [0, 1], of subsequently drawn tile
+// images.
+func (r *Image) SetOpacity(opacity float64) {
+	if opacity >= 1 {
+		r.mask = nil
+		return
+	}
+	r.mask = image.NewUniform(color.Alpha{A: uint8(opacity*0xFF + 0.5)})
+}
+
+// SetScale sets the scale factor of subsequently drawn tile images. Tile images
+// are scaled using nearest-neighbour interpolation.
+func (r *Image) SetScale(scale float64) {
+	if scale == r.scale {
+		return
+	}
+	r.scale = scale
+	r.scaled = nil
+}
+
+// scaleTile returns the tile image scaled by the scale factor of the renderer.
+// The size of the scaled tile image is rounded up, so that adjacent tiles leave
+// no gaps.
+func (r *Image) scaleTile(tile image.Image) image.Image {
+	if scaled, ok := r.scaled[tile]; ok {
+		return scaled
+	}
+	src := tile.Bounds()
+	w := int(math.Ceil(float64(src.Dx()) * r.scale))
+	h := int(math.Ceil(float64(src.Dy()) * r.scale))
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		sy := src.Min.Y + int(float64(y)/r.scale)
+		if sy >= src.Max.Y {
+			sy = src.Max.Y - 1
+		}
+		for x := 0; x < w; x++ {
+			sx := src.Min.X + int(float64(x)/r.scale)
+			if sx >= src.Max.X {
+				sx = src.Max.X - 1
+			}
+			dst.Set(x, y, tile.At(sx, sy))
+		}
+	}
+	if r.scaled == nil {
+		r.scaled = make(map[image.Image]image.Image)
+	}
+	r.scaled[tile] = dst
+	return dst
+}
+
+// FillRect blends the color c over the destination rectangle dr.
+func (r *Image) FillRect(dr image.Rectangle, c color.Color) {
+	draw.Draw(r.Dst, dr, image.NewUniform(c), image.ZP, draw.Over)
+}
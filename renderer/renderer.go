@@ -0,0 +1,194 @@
+// Package renderer draws maps through views, independent of the graphics
+// backend.
+package renderer
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/mewmew/pgg/fog"
+	"github.com/mewmew/pgg/grid"
+	"github.com/mewmew/pgg/tileset"
+	"github.com/mewmew/pgg/view"
+)
+
+// A Drawer draws tile images.
+type Drawer interface {
+	// DrawTile draws the tile image specified by id at the destination point
+	// dp.
+	DrawTile(id tileset.TileID, dp image.Point)
+}
+
+// A Scaler is a drawer which scales drawn tile images.
+type Scaler interface {
+	// SetScale sets the scale factor of subsequently drawn tile images.
+	SetScale(scale float64)
+}
+
+// A Renderer is a drawer which controls the scaling and blending of drawn tile
+// images.
+type Renderer interface {
+	Drawer
+	Scaler
+	// SetOpacity sets the opacity, in the range [0, 1], of subsequently drawn
+	// tile images.
+	SetOpacity(opacity float64)
+	// FillRect blends the color c over the destination rectangle dr.
+	FillRect(dr image.Rectangle, c color.Color)
+}
+
+// DrawMap draws the portion of the map visible through the view. Cells without
+// a valid tile identifier are skipped. Tile images are scaled by the zoom
+// factor of the view if d is a Scaler.
+func DrawMap(d Drawer, m *grid.Map, v *view.View) {
+	setScale(d, v)
+	eachCell(v, m.Cols(), m.Rows(), func(loc grid.Location, dr image.Rectangle) {
+		id := m.Cells[loc.Col][loc.Row]
+		if !id.IsValid() {
+			return
+		}
+		d.DrawTile(id, dr.Min)
+	})
+}
+
+// DrawChunked draws the portion of the chunked map visible through the view.
+// Only the loaded chunks in range of the view are visited; cells of chunks
+// which are not loaded, and cells without a valid tile identifier, are skipped.
+// The view is typically unclamped, as chunked maps are unbounded. Tile images
+// are scaled by the zoom factor of the view if d is a Scaler.
+func DrawChunked(d Drawer, cm *grid.ChunkedMap, v *view.View) {
+	setScale(d, v)
+	min, max := v.Range()
+	cm.EachChunk(min, max, func(chunk *grid.Chunk) {
+		for col, cells := range chunk.Cells {
+			for row, id := range cells {
+				loc := grid.Loc(chunk.Origin.Col+col, chunk.Origin.Row+row)
+				if !id.IsValid() || loc.Col < min.Col || loc.Col > max.Col || loc.Row < min.Row || loc.Row > max.Row {
+					continue
+				}
+				d.DrawTile(id, v.WorldToScreen(cm.Pixel(loc)))
+			}
+		}
+	})
+}
+
+// DrawProjected draws the portion of the map visible through the view, with its
+// cells laid out by the provided projection, such as the layout of a hexagonal
+// or an isometric map. Cells are drawn back to front in the order of the
+// projection. Cells without a valid tile identifier are skipped. Tile images are
+// scaled by the zoom factor of the view if d is a Scaler.
+func DrawProjected(d Drawer, m *grid.Map, v *view.View, proj grid.Projection) {
+	setScale(d, v)
+	bounds := v.Bounds()
+	min, max := proj.Range(bounds)
+	min, max = clampRange(min, max, m.Cols(), m.Rows(), v.Wrap)
+	proj.Order(min, max, func(loc grid.Location) {
+		if !proj.CellBounds(loc).Overlaps(bounds) {
+			return
+		}
+		cell, ok := v.Wrap.Normalize(loc, m.Cols(), m.Rows())
+		if !ok {
+			return
+		}
+		id := m.Cells[cell.Col][cell.Row]
+		if !id.IsValid() {
+			return
+		}
+		d.DrawTile(id, v.WorldToScreen(proj.Pixel(loc)))
+	})
+}
+
+// DrawProjectedLayers draws the portion of the layered map visible through the
+// view, with its cells laid out by the provided projection, compositing its
+// visible layers bottom to top.
+func DrawProjectedLayers(r Renderer, lm *grid.LayeredMap, v *view.View, proj grid.Projection) {
+	for _, l := range lm.DrawOrder() {
+		r.SetOpacity(l.Opacity)
+		DrawProjected(r, l.Map, v, proj)
+	}
+	r.SetOpacity(1)
+}
+
+// DrawLayers draws the portion of the layered map visible through the view,
+// compositing its visible layers bottom to top.
+func DrawLayers(r Renderer, lm *grid.LayeredMap, v *view.View) {
+	for _, l := range lm.DrawOrder() {
+		r.SetOpacity(l.Opacity)
+		DrawMap(r, l.Map, v)
+	}
+	r.SetOpacity(1)
+}
+
+// Fog of war colors of unexplored and explored cells.
+var (
+	unexploredColor = color.Alpha{A: 0xFF}
+	exploredColor   = color.Alpha{A: 0x80}
+)
+
+// DrawFog draws the fog of war over the portion of the map visible through the
+// view; unexplored cells are drawn black, explored cells which are not
+// currently visible are dimmed and visible cells are left as is.
+func DrawFog(r Renderer, fm *fog.Map, v *view.View) {
+	eachCell(v, fm.Cols(), fm.Rows(), func(loc grid.Location, dr image.Rectangle) {
+		switch fm.State(loc) {
+		case fog.Unexplored:
+			r.FillRect(dr, unexploredColor)
+		case fog.Explored:
+			r.FillRect(dr, exploredColor)
+		}
+	})
+}
+
+// setScale sets the scale factor of d to the zoom factor of the view, if d is a
+// Scaler.
+func setScale(d Drawer, v *view.View) {
+	if s, ok := d.(Scaler); ok {
+		s.SetScale(v.Zoom())
+	}
+}
+
+// eachCell invokes fn for each cell visible through the view, with the grid
+// location of the cell and its destination rectangle. Only cells within a map of
+// the specified number of columns and rows are visited; on wrapping worlds,
+// cells visible more than once are visited once per copy, with normalized
+// locations.
+func eachCell(v *view.View, cols, rows int, fn func(loc grid.Location, dr image.Rectangle)) {
+	geom := v.Geometry()
+	min, max := v.Range()
+	min, max = clampRange(min, max, cols, rows, v.Wrap)
+	for col := min.Col; col <= max.Col; col++ {
+		for row := min.Row; row <= max.Row; row++ {
+			loc := grid.Loc(col, row)
+			cell, ok := v.Wrap.Normalize(loc, cols, rows)
+			if !ok {
+				continue
+			}
+			bounds := geom.CellBounds(loc)
+			dr := image.Rectangle{Min: v.WorldToScreen(bounds.Min), Max: v.WorldToScreen(bounds.Max)}
+			fn(cell, dr)
+		}
+	}
+}
+
+// clampRange clamps the inclusive range of cells between min and max to a map
+// of the specified number of columns and rows, along the axes which do not
+// wrap around.
+func clampRange(min, max grid.Location, cols, rows int, wrap grid.Wrap) (grid.Location, grid.Location) {
+	if wrap&grid.WrapHorizontal == 0 {
+		if min.Col < 0 {
+			min.Col = 0
+		}
+		if max.Col >= cols {
+			max.Col = cols - 1
+		}
+	}
+	if wrap&grid.WrapVertical == 0 {
+		if min.Row < 0 {
+			min.Row = 0
+		}
+		if max.Row >= rows {
+			max.Row = rows - 1
+		}
+	}
+	return min, max
+}
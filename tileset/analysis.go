@@ -0,0 +1,97 @@
+package tileset
+
+import (
+	"image"
+	"image/draw"
+)
+
+// An analysis records the content of the tile images of a tile set.
+type analysis struct {
+	// empty[id-1] specifies whether the tile image of id is fully transparent.
+	empty []bool
+	// orig[id-1] specifies the first tile identifier with a tile image
+	// byte-identical to that of id; which is id itself for unique tile images.
+	orig []TileID
+	// Last tile identifier with a non-empty tile image.
+	lastUsed TileID
+}
+
+// analyze analyzes the content of the tile images of the tile set, if not
+// already analyzed.
+func (ts *TileSet) analyze() *analysis {
+	if ts.analysis != nil {
+		return ts.analysis
+	}
+	last := ts.LastID()
+	a := &analysis{
+		empty: make([]bool, last),
+		orig:  make([]TileID, last),
+	}
+	// Mapping from tile image pixels to the first tile identifier of the tile
+	// image.
+	seen := make(map[string]TileID)
+	bounds := image.Rect(0, 0, ts.TileWidth, ts.TileHeight)
+	dst := image.NewNRGBA(bounds)
+	for id := TileID(1); id <= last; id++ {
+		tile := ts.tile(id)
+		draw.Draw(dst, bounds, tile, tile.Bounds().Min, draw.Src)
+		if isTransparent(dst) {
+			a.empty[id-1] = true
+		} else {
+			a.lastUsed = id
+		}
+		pix := string(dst.Pix)
+		orig, ok := seen[pix]
+		if !ok {
+			orig = id
+			seen[pix] = id
+		}
+		a.orig[id-1] = orig
+	}
+	ts.analysis = a
+	return a
+}
+
+// isTransparent reports whether every pixel of img is fully transparent.
+func isTransparent(img *image.NRGBA) bool {
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsEmpty returns true if the tile image specified by id is fully transparent.
+func (ts *TileSet) IsEmpty(id TileID) bool {
+	id = id.Base()
+	if !id.IsValid() || id > ts.LastID() {
+		return true
+	}
+	return ts.analyze().empty[id-1]
+}
+
+// Original returns the first tile identifier of the tile set whose tile image
+// is byte-identical to the tile image specified by id. For tile images without
+// earlier duplicates, the returned tile identifier is id itself. The flip flags
+// of id are ignored.
+func (ts *TileSet) Original(id TileID) TileID {
+	id = id.Base()
+	if !id.IsValid() || id > ts.LastID() {
+		return id
+	}
+	return ts.analyze().orig[id-1]
+}
+
+// IsDuplicate returns true if the tile image specified by id is byte-identical
+// to the tile image of a lower tile identifier of the tile set.
+func (ts *TileSet) IsDuplicate(id TileID) bool {
+	return ts.Original(id) != id.Base()
+}
+
+// LastUsedID returns the last tile identifier of the tile set with a tile image
+// that is not fully transparent. A tile set without non-empty tile images
+// always returns the zero value.
+func (ts *TileSet) LastUsedID() TileID {
+	return ts.analyze().lastUsed
+}
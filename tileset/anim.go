@@ -0,0 +1,96 @@
+package tileset
+
+import (
+	"time"
+)
+
+// A Frame is a single frame of an animated tile.
+type Frame struct {
+	// Tile identifier of the frame.
+	ID TileID
+	// Duration of the frame.
+	Duration time.Duration
+}
+
+// An Animation is a looping sequence of frames.
+type Animation []Frame
+
+// Duration returns the duration of a single loop of the animation.
+func (anim Animation) Duration() (d time.Duration) {
+	for _, frame := range anim {
+		d += frame.Duration
+	}
+	return d
+}
+
+// At returns the tile identifier of the frame shown at the elapsed time t of
+// the animation. The zero value is returned for animations without frames.
+func (anim Animation) At(t time.Duration) TileID {
+	if len(anim) == 0 {
+		return 0
+	}
+	total := anim.Duration()
+	if total <= 0 {
+		return anim[0].ID
+	}
+	t %= total
+	if t < 0 {
+		t += total
+	}
+	for _, frame := range anim {
+		if t < frame.Duration {
+			return frame.ID
+		}
+		t -= frame.Duration
+	}
+	return anim[len(anim)-1].ID
+}
+
+// A Clock keeps track of the time elapsed since the start of animations.
+type Clock struct {
+	// Elapsed time.
+	now time.Duration
+}
+
+// Advance advances the clock by the duration dt.
+func (c *Clock) Advance(dt time.Duration) {
+	c.now += dt
+}
+
+// Now returns the time elapsed since the start of the clock.
+func (c *Clock) Now() time.Duration {
+	return c.now
+}
+
+// An Animator resolves animated tiles to the frame shown at the current time
+// of its clock.
+type Animator struct {
+	// Mapping from animated tile identifiers to animations.
+	Animations map[TileID]Animation
+	// Clock driving the animations; or nil to always show the first frame.
+	Clock *Clock
+}
+
+// Animate defines the animated tile specified by id, which is shown as the
+// provided looping sequence of frames.
+func (a *Animator) Animate(id TileID, anim Animation) {
+	if a.Animations == nil {
+		a.Animations = make(map[TileID]Animation)
+	}
+	a.Animations[id.Base()] = anim
+}
+
+// Frame returns the tile identifier of the frame currently shown for the tile
+// specified by id. Tile identifiers without animations are returned unchanged.
+// The flip flags of id are retained.
+func (a *Animator) Frame(id TileID) TileID {
+	anim, ok := a.Animations[id.Base()]
+	if !ok {
+		return id
+	}
+	var now time.Duration
+	if a.Clock != nil {
+		now = a.Clock.Now()
+	}
+	return anim.At(now) | id.Flags()
+}
@@ -0,0 +1,95 @@
+package tileset
+
+import (
+	"image"
+)
+
+// A Layout specifies the arrangement of tile images within a sprite sheet.
+type Layout struct {
+	// Tile width.
+	TileWidth int
+	// Tile height.
+	TileHeight int
+	// Margin in pixels around the tile images of the sprite sheet.
+	Margin int
+	// Spacing in pixels between adjacent tile images.
+	Spacing int
+	// Origin offset of the tile images relative to the top left point of the
+	// sprite sheet. The margin is applied after the offset.
+	Offset image.Point
+}
+
+// An Option specifies a layout option of a sprite sheet.
+type Option func(l *Layout)
+
+// Margin specifies the margin in pixels around the tile images of the sprite
+// sheet.
+func Margin(margin int) Option {
+	return func(l *Layout) {
+		l.Margin = margin
+	}
+}
+
+// Spacing specifies the spacing in pixels between adjacent tile images.
+func Spacing(spacing int) Option {
+	return func(l *Layout) {
+		l.Spacing = spacing
+	}
+}
+
+// Offset specifies the origin offset of the tile images relative to the top
+// left point of the sprite sheet.
+func Offset(off image.Point) Option {
+	return func(l *Layout) {
+		l.Offset = off
+	}
+}
+
+// NewLayout returns a layout of tile images with the specified width and
+// height, and the provided layout options applied. Tile images are packed edge
+// to edge starting at the top left point of the sprite sheet, unless otherwise
+// specified by the options.
+func NewLayout(tileWidth, tileHeight int, opts ...Option) (l Layout) {
+	l = Layout{
+		TileWidth:  tileWidth,
+		TileHeight: tileHeight,
+	}
+	for _, opt := range opts {
+		opt(&l)
+	}
+	return l
+}
+
+// Dims returns the number of tile columns and rows of a sprite sheet with the
+// specified width and height. Layouts without a positive tile size have no
+// tiles.
+func (l Layout) Dims(width, height int) (cols, rows int) {
+	if l.TileWidth <= 0 || l.TileHeight <= 0 {
+		return 0, 0
+	}
+	cols = (width - l.Offset.X - 2*l.Margin + l.Spacing) / (l.TileWidth + l.Spacing)
+	rows = (height - l.Offset.Y - 2*l.Margin + l.Spacing) / (l.TileHeight + l.Spacing)
+	if cols < 0 {
+		cols = 0
+	}
+	if rows < 0 {
+		rows = 0
+	}
+	return cols, rows
+}
+
+// TileBounds returns the bounding rectangle of the tile image specified by id
+// in a sprite sheet with the specified number of tile columns. The flip flags
+// of id are ignored. The bounds are empty for sprite sheets without tile
+// columns, such as sheets narrower than a single tile.
+func (l Layout) TileBounds(id TileID, cols int) image.Rectangle {
+	if cols <= 0 {
+		return image.Rectangle{}
+	}
+	i := int(id.Base() - 1)
+	col := i % cols
+	row := i / cols
+	x := l.Offset.X + l.Margin + col*(l.TileWidth+l.Spacing)
+	y := l.Offset.Y + l.Margin + row*(l.TileHeight+l.Spacing)
+	return image.Rect(x, y, x+l.TileWidth, y+l.TileHeight)
+}
@@ -0,0 +1,28 @@
+package tileset
+
+import (
+	"image"
+	"testing"
+)
+
+func TestTileBounds(t *testing.T) {
+	golden := []struct {
+		name          string
+		layout        Layout
+		width, height int
+		id            TileID
+		want          image.Rectangle
+	}{
+		{name: "first tile", layout: NewLayout(16, 16), width: 64, height: 32, id: 1, want: image.Rect(0, 0, 16, 16)},
+		{name: "second row", layout: NewLayout(16, 16, Margin(1), Spacing(2)), width: 64, height: 64, id: 5, want: image.Rect(19, 19, 35, 35)},
+		{name: "flipped tile", layout: NewLayout(16, 16), width: 64, height: 32, id: 6 | FlipHorizontal, want: image.Rect(16, 16, 32, 32)},
+		{name: "zero layout", layout: Layout{}, width: 64, height: 32, id: 1, want: image.Rectangle{}},
+		{name: "sheet narrower than tile", layout: NewLayout(16, 16, Margin(4)), width: 20, height: 32, id: 1, want: image.Rectangle{}},
+	}
+	for _, g := range golden {
+		cols, _ := g.layout.Dims(g.width, g.height)
+		if got := g.layout.TileBounds(g.id, cols); got != g.want {
+			t.Errorf("%s: tile bounds mismatch; expected %v, got %v", g.name, g.want, got)
+		}
+	}
+}
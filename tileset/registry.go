@@ -0,0 +1,103 @@
+package tileset
+
+import (
+	"fmt"
+	"image"
+	"sort"
+)
+
+// A Registry is a collection of tile sources, such as tile sets, each of which
+// is assigned a range of global tile identifiers. A global tile identifier
+// resolves through the registry to a tile set and a tile identifier local to
+// that tile set, in the style of the first global tile identifiers of the Tiled
+// map editor.
+type Registry struct {
+	// Registered tile sets, in ascending order of first global tile
+	// identifiers.
+	entries []entry
+}
+
+// An entry is a tile source registered at a given first global tile
+// identifier.
+type entry struct {
+	// First global tile identifier of the tile source.
+	firstID TileID
+	// Last global tile identifier of the tile source.
+	lastID TileID
+	// Tile source.
+	src Source
+}
+
+// NewRegistry returns a new registry without tile sets.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Add adds the tile source to the registry, assigning it the range of global
+// tile identifiers directly following those of the tile sources already
+// registered. It returns the first global tile identifier of the tile source.
+func (r *Registry) Add(src Source) (firstID TileID) {
+	firstID = 1
+	if n := len(r.entries); n > 0 {
+		firstID = r.entries[n-1].lastID + 1
+	}
+	r.entries = append(r.entries, entry{firstID: firstID, lastID: firstID + src.LastID() - 1, src: src})
+	return firstID
+}
+
+// AddAt adds the tile source to the registry, assigning it the range of global
+// tile identifiers starting at firstID. It is an error for the range to
+// overlap that of an already registered tile source.
+func (r *Registry) AddAt(src Source, firstID TileID) error {
+	if !firstID.IsValid() || firstID.Flags() != 0 {
+		return fmt.Errorf("tileset: invalid first global tile identifier %d", firstID)
+	}
+	e := entry{firstID: firstID, lastID: firstID + src.LastID() - 1, src: src}
+	i := sort.Search(len(r.entries), func(i int) bool {
+		return r.entries[i].firstID > firstID
+	})
+	if i > 0 && r.entries[i-1].lastID >= e.firstID {
+		return fmt.Errorf("tileset: global tile identifier range [%d, %d] overlaps [%d, %d]", e.firstID, e.lastID, r.entries[i-1].firstID, r.entries[i-1].lastID)
+	}
+	if i < len(r.entries) && r.entries[i].firstID <= e.lastID {
+		return fmt.Errorf("tileset: global tile identifier range [%d, %d] overlaps [%d, %d]", e.firstID, e.lastID, r.entries[i].firstID, r.entries[i].lastID)
+	}
+	r.entries = append(r.entries, entry{})
+	copy(r.entries[i+1:], r.entries[i:])
+	r.entries[i] = e
+	return nil
+}
+
+// Lookup resolves the global tile identifier id to the tile source containing
+// it and the corresponding local tile identifier. The flip flags of id are
+// retained by the local tile identifier. The boolean return value is false if
+// no registered tile source contains id.
+func (r *Registry) Lookup(id TileID) (src Source, local TileID, ok bool) {
+	base := id.Base()
+	if !base.IsValid() {
+		return nil, 0, false
+	}
+	i := sort.Search(len(r.entries), func(i int) bool {
+		return r.entries[i].lastID >= base
+	})
+	if i == len(r.entries) || r.entries[i].firstID > base {
+		return nil, 0, false
+	}
+	e := r.entries[i]
+	local = (base - e.firstID + 1) | id.Flags()
+	return e.src, local, true
+}
+
+// Tile returns the tile image specified by the global tile identifier id, or
+// nil if no registered tile set of the image backend contains id.
+func (r *Registry) Tile(id TileID) image.Image {
+	src, local, ok := r.Lookup(id)
+	if !ok {
+		return nil
+	}
+	ts, ok := src.(*TileSet)
+	if !ok {
+		return nil
+	}
+	return ts.Tile(local)
+}
@@ -12,81 +12,155 @@ import (
 type TileSet struct {
 	// Tile set sprite sheet.
 	imgutil.SubImager
-	// Tile width.
-	TileWidth int
-	// Tile height.
-	TileHeight int
-	// Tile set width and height.
-	width, height int
+	// Layout of the tile images within the sprite sheet.
+	Layout
+	// Animated tiles of the tile set.
+	Animator
+	// Tile set width and height in number of tile columns and rows
+	// respectively.
+	cols, rows int
 	// Mapping from tile identifiers to tile images.
 	tiles map[TileID]image.Image
+	// Content analysis of the tile images; or nil if not yet analyzed.
+	analysis *analysis
 }
 
-// New returns a tile set based on the provided sprite sheet img.
-func New(img image.Image, tileWidth, tileHeight int) (ts *TileSet) {
+// New returns a tile set based on the provided sprite sheet img. The tile
+// images are arranged within the sprite sheet as specified by the optional
+// layout options.
+func New(img image.Image, tileWidth, tileHeight int, opts ...Option) (ts *TileSet) {
 	ts = &TileSet{
-		TileWidth:  tileWidth,
-		TileHeight: tileHeight,
-		tiles:      make(map[TileID]image.Image),
+		Layout: NewLayout(tileWidth, tileHeight, opts...),
+		tiles:  make(map[TileID]image.Image),
 	}
 	ts.SubImager = imgutil.SubFallback(img)
 	bounds := ts.Bounds()
-	ts.width = bounds.Dx()
-	ts.height = bounds.Dy()
+	ts.cols, ts.rows = ts.Dims(bounds.Dx(), bounds.Dy())
 	return ts
 }
 
 // Open opens the sprite sheet specified by imgPath and returns a tile set based
-// upon it.
-func Open(imgPath string, tileWidth, tileHeight int) (ts *TileSet, err error) {
+// upon it. The tile images are arranged within the sprite sheet as specified
+// by the optional layout options.
+func Open(imgPath string, tileWidth, tileHeight int, opts ...Option) (ts *TileSet, err error) {
 	img, err := imgutil.ReadFile(imgPath)
 	if err != nil {
 		return nil, err
 	}
-	ts = New(img, tileWidth, tileHeight)
+	ts = New(img, tileWidth, tileHeight, opts...)
 	return ts, nil
 }
 
 // A TileID uniquely identifies a tile image in a specific tile set. The zero
-// value represents no tile image.
+// value represents no tile image. Tile identifiers are shared by the tile sets
+// of all graphics backends.
 type TileID int
 
 // IsValid returns true if the tile identifier is valid and false if it's the
 // zero value.
 func (id TileID) IsValid() bool {
-	return id != 0
+	return id.Base() != 0
 }
 
-// tileRect returns the bounding rectangle of the tile image in the sprite
-// sheet.
-func (ts *TileSet) tileRect(id TileID) image.Rectangle {
-	tsCols := ts.width / ts.TileWidth
-	i := int(id - 1)
-	col := i % tsCols
-	row := i / tsCols
-	x := col * ts.TileWidth
-	y := row * ts.TileHeight
-	return image.Rect(x, y, x+ts.TileWidth, y+ts.TileHeight)
+// Flip flags of tile identifiers. The flags are stored in the high bits of a
+// TileID and specify how the tile image is transformed when drawn. The diagonal
+// flip is applied first, followed by the horizontal and vertical flips.
+const (
+	// FlipHorizontal mirrors the tile image horizontally.
+	FlipHorizontal TileID = 1 << 30
+	// FlipVertical mirrors the tile image vertically.
+	FlipVertical TileID = 1 << 29
+	// FlipDiagonal mirrors the tile image along its top left to bottom right
+	// diagonal.
+	FlipDiagonal TileID = 1 << 28
+	// flipMask is the union of all flip flags.
+	flipMask = FlipHorizontal | FlipVertical | FlipDiagonal
+)
+
+// Base returns the tile identifier with its flip flags cleared.
+func (id TileID) Base() TileID {
+	return id &^ flipMask
+}
+
+// Flags returns the flip flags of the tile identifier.
+func (id TileID) Flags() TileID {
+	return id & flipMask
+}
+
+// A Source is a source of tile images, such as the tile sets of the image and
+// the OpenGL backends.
+type Source interface {
+	// LastID returns the last tile identifier contained within the tile source.
+	// An empty tile source always returns the zero value.
+	LastID() TileID
+	// TileBounds returns the bounding rectangle of the tile image specified by
+	// id in the sprite sheet of the tile source.
+	TileBounds(id TileID) image.Rectangle
+}
+
+// TileBounds returns the bounding rectangle of the tile image specified by id
+// in the sprite sheet. The flip flags of id are ignored.
+func (ts *TileSet) TileBounds(id TileID) image.Rectangle {
+	return ts.Layout.TileBounds(id, ts.cols)
 }
 
-// Tile returns the tile image specified by id from the tile set.
+// Tile returns the tile image specified by id from the tile set. Animated tiles
+// return the tile image of their current frame. The flip flags of id, if any,
+// are applied to the returned image.
 func (ts *TileSet) Tile(id TileID) image.Image {
+	return ts.tile(ts.Frame(id))
+}
+
+// tile returns the tile image specified by id from the tile set, without
+// resolving animated tiles.
+func (ts *TileSet) tile(id TileID) image.Image {
 	tile, ok := ts.tiles[id]
 	if !ok {
 		// Create the tile image as a subimage of the sprite sheet.
-		rect := ts.tileRect(id)
+		rect := ts.TileBounds(id)
 		tile = ts.SubImage(rect)
+		if flags := id.Flags(); flags != 0 {
+			tile = flip(tile, flags)
+		}
 		ts.tiles[id] = tile
 	}
 	return tile
 }
 
+// flip returns a copy of the tile image with the provided flip flags applied.
+func flip(tile image.Image, flags TileID) image.Image {
+	src := tile.Bounds()
+	w, h := src.Dx(), src.Dy()
+	if flags&FlipDiagonal != 0 {
+		w, h = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			// Undo the flips in reverse order to locate the source pixel.
+			sx, sy := x, y
+			if flags&FlipVertical != 0 {
+				sy = h - 1 - sy
+			}
+			if flags&FlipHorizontal != 0 {
+				sx = w - 1 - sx
+			}
+			if flags&FlipDiagonal != 0 {
+				sx, sy = sy, sx
+			}
+			dst.Set(x, y, tile.At(src.Min.X+sx, src.Min.Y+sy))
+		}
+	}
+	return dst
+}
+
 // LastID returns the last tile identifier contained within the tile set. An
 // empty tile set always returns the zero value.
+//
+// Every tile of the sprite sheet is counted, including trailing empty tiles,
+// so that the range of tile identifiers of the tile set remains stable. Use
+// LastUsedID to ignore trailing empty tiles.
 func (ts *TileSet) LastID() (id TileID) {
-	// TODO(u): ignore trailing empty tiles?
-	tsCols := ts.width / ts.TileWidth
-	tsRows := ts.height / ts.TileHeight
-	id = TileID(tsCols * tsRows)
+	id = TileID(ts.cols * ts.rows)
 	return id
 }
@@ -0,0 +1,126 @@
+package tmx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/mewmew/pgg/tileset"
+)
+
+// xmlData is the XML representation of the tile data of a TMX layer.
+type xmlData struct {
+	Encoding    string    `xml:"encoding,attr"`
+	Compression string    `xml:"compression,attr"`
+	Tiles       []xmlTile `xml:"tile"`
+	Text        string    `xml:",chardata"`
+}
+
+// xmlTile is the XML representation of a tile in XML encoded layer data.
+type xmlTile struct {
+	GID uint32 `xml:"gid,attr"`
+}
+
+// decode decodes the n global tile identifiers of the layer data.
+func (data xmlData) decode(n int) (gids []uint32, err error) {
+	switch data.Encoding {
+	case "":
+		for _, tile := range data.Tiles {
+			gids = append(gids, tile.GID)
+		}
+	case "csv":
+		gids, err = decodeCSV(data.Text)
+	case "base64":
+		gids, err = decodeBase64(data.Text, data.Compression)
+	default:
+		return nil, fmt.Errorf("unsupported data encoding %q", data.Encoding)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(gids) != n {
+		return nil, fmt.Errorf("invalid number of tiles; expected %d, got %d", n, len(gids))
+	}
+	return gids, nil
+}
+
+// decodeCSV decodes the global tile identifiers of CSV encoded layer data.
+func decodeCSV(text string) (gids []uint32, err error) {
+	for _, field := range strings.Split(text, ",") {
+		field = strings.TrimSpace(field)
+		if len(field) == 0 {
+			continue
+		}
+		gid, err := strconv.ParseUint(field, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		gids = append(gids, uint32(gid))
+	}
+	return gids, nil
+}
+
+// decodeBase64 decodes the global tile identifiers of base64 encoded layer
+// data, which is optionally compressed using zlib or gzip.
+func decodeBase64(text, compression string) (gids []uint32, err error) {
+	buf, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+	if err != nil {
+		return nil, err
+	}
+	var r io.Reader = bytes.NewReader(buf)
+	switch compression {
+	case "":
+	case "zlib":
+		r, err = zlib.NewReader(r)
+	case "gzip":
+		r, err = gzip.NewReader(r)
+	default:
+		return nil, fmt.Errorf("unsupported data compression %q", compression)
+	}
+	if err != nil {
+		return nil, err
+	}
+	buf, err = ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf)%4 != 0 {
+		return nil, fmt.Errorf("invalid data length %d; not a multiple of 4", len(buf))
+	}
+	for i := 0; i < len(buf); i += 4 {
+		gids = append(gids, binary.LittleEndian.Uint32(buf[i:]))
+	}
+	return gids, nil
+}
+
+// Flip flags stored in the high bits of Tiled global tile identifiers.
+const (
+	flippedHorizontally = 0x80000000
+	flippedVertically   = 0x40000000
+	flippedDiagonally   = 0x20000000
+	rotatedHexagonal120 = 0x10000000
+	gidFlags            = flippedHorizontally | flippedVertically | flippedDiagonally | rotatedHexagonal120
+)
+
+// tileID returns the tile identifier corresponding to the provided Tiled
+// global tile identifier, translating its flip flags.
+func tileID(gid uint32) tileset.TileID {
+	id := tileset.TileID(gid &^ gidFlags)
+	if gid&flippedHorizontally != 0 {
+		id |= tileset.FlipHorizontal
+	}
+	if gid&flippedVertically != 0 {
+		id |= tileset.FlipVertical
+	}
+	if gid&flippedDiagonally != 0 {
+		id |= tileset.FlipDiagonal
+	}
+	return id
+}
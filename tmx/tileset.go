@@ -0,0 +1,141 @@
+package tmx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mewmew/pgg/tileset"
+)
+
+// A TileSet is a tile set loaded from a TMX or TSX file.
+type TileSet struct {
+	// First global tile identifier of the tile set within a map; tile
+	// identifier 1 of the tile set corresponds to global tile identifier
+	// FirstID.
+	FirstID tileset.TileID
+	// Tile set name.
+	Name string
+	// Path to the sprite sheet of the tile set.
+	ImagePath string
+	// Tile set sprite sheet.
+	*tileset.TileSet
+}
+
+// Registry returns a tile set registry which resolves the global tile
+// identifiers of the map cells, as assigned by the first global tile
+// identifiers of the tile sets.
+func (m *Map) Registry() (r *tileset.Registry, err error) {
+	r = tileset.NewRegistry()
+	for _, ts := range m.TileSets {
+		err = r.AddAt(ts.TileSet, ts.FirstID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// OpenTileSet opens the TSX file specified by tsxPath and returns the tile set
+// it contains. The sprite sheet is located relative to the directory of the TSX
+// file.
+func OpenTileSet(tsxPath string) (ts *TileSet, err error) {
+	xts, err := readTSX(tsxPath)
+	if err != nil {
+		return nil, err
+	}
+	xts.FirstGID = 1
+	return loadTileSet(xts, filepath.Dir(tsxPath))
+}
+
+// loadTileSet loads the provided tile set, reading external tile sets and
+// sprite sheets relative to dir.
+func loadTileSet(xts xmlTileSet, dir string) (ts *TileSet, err error) {
+	if len(xts.Source) > 0 {
+		// Load external tile set; the first global tile identifier is always
+		// specified by the map.
+		firstGID := xts.FirstGID
+		tsxPath := filepath.Join(dir, xts.Source)
+		xts, err = readTSX(tsxPath)
+		if err != nil {
+			return nil, err
+		}
+		xts.FirstGID = firstGID
+		dir = filepath.Dir(tsxPath)
+	}
+	if len(xts.Image.Source) == 0 {
+		return nil, fmt.Errorf("tmx: tile set %q: image collection tile sets are not supported", xts.Name)
+	}
+	ts = &TileSet{
+		FirstID:   tileset.TileID(xts.FirstGID),
+		Name:      xts.Name,
+		ImagePath: filepath.Join(dir, xts.Image.Source),
+	}
+	ts.TileSet, err = tileset.Open(ts.ImagePath, xts.TileWidth, xts.TileHeight, tileset.Margin(xts.Margin), tileset.Spacing(xts.Spacing))
+	if err != nil {
+		return nil, err
+	}
+	// Define animated tiles; the local tile identifiers of Tiled start at 0.
+	for _, tile := range xts.Tiles {
+		if len(tile.Frames) == 0 {
+			continue
+		}
+		var anim tileset.Animation
+		for _, frame := range tile.Frames {
+			f := tileset.Frame{
+				ID:       tileset.TileID(frame.TileID + 1),
+				Duration: time.Duration(frame.Duration) * time.Millisecond,
+			}
+			anim = append(anim, f)
+		}
+		ts.Animate(tileset.TileID(tile.ID+1), anim)
+	}
+	return ts, nil
+}
+
+// readTSX reads and decodes the TSX file specified by tsxPath.
+func readTSX(tsxPath string) (xts xmlTileSet, err error) {
+	f, err := os.Open(tsxPath)
+	if err != nil {
+		return xmlTileSet{}, err
+	}
+	defer f.Close()
+	err = xml.NewDecoder(f).Decode(&xts)
+	if err != nil {
+		return xmlTileSet{}, fmt.Errorf("tmx: unable to decode %q: %v", tsxPath, err)
+	}
+	return xts, nil
+}
+
+// xmlTileSet is the XML representation of a TMX or TSX tile set.
+type xmlTileSet struct {
+	FirstGID   uint32       `xml:"firstgid,attr"`
+	Source     string       `xml:"source,attr"`
+	Name       string       `xml:"name,attr"`
+	TileWidth  int          `xml:"tilewidth,attr"`
+	TileHeight int          `xml:"tileheight,attr"`
+	Spacing    int          `xml:"spacing,attr"`
+	Margin     int          `xml:"margin,attr"`
+	Image      xmlImage     `xml:"image"`
+	Tiles      []xmlTileDef `xml:"tile"`
+}
+
+// xmlTileDef is the XML representation of the properties of a tile in a tile
+// set.
+type xmlTileDef struct {
+	ID     uint32     `xml:"id,attr"`
+	Frames []xmlFrame `xml:"animation>frame"`
+}
+
+// xmlFrame is the XML representation of a frame of an animated tile.
+type xmlFrame struct {
+	TileID   uint32 `xml:"tileid,attr"`
+	Duration int    `xml:"duration,attr"`
+}
+
+// xmlImage is the XML representation of a TMX image.
+type xmlImage struct {
+	Source string `xml:"source,attr"`
+}
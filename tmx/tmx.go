@@ -0,0 +1,128 @@
+// Package tmx loads maps and tile sets stored in the TMX and TSX formats of the
+// Tiled map editor.
+//
+// Reference: http://doc.mapeditor.org/reference/tmx-map-format/
+package tmx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/mewmew/pgg/grid"
+)
+
+// A Map is a map loaded from a TMX file. The cells of each layer hold global
+// tile identifiers.
+type Map struct {
+	// Tile layers of the map.
+	*grid.LayeredMap
+	// Tile sets used by the map, in ascending order of first global tile
+	// identifiers.
+	TileSets []*TileSet
+}
+
+// Open opens the TMX file specified by tmxPath and returns the map it
+// contains. External tile sets and sprite sheets are located relative to the
+// directory of the TMX file.
+func Open(tmxPath string) (m *Map, err error) {
+	f, err := os.Open(tmxPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Decode(f, filepath.Dir(tmxPath))
+}
+
+// Decode decodes the TMX map read from r. External tile sets and sprite sheets
+// are located relative to dir.
+func Decode(r io.Reader, dir string) (m *Map, err error) {
+	var xm xmlMap
+	err = xml.NewDecoder(r).Decode(&xm)
+	if err != nil {
+		return nil, err
+	}
+	if xm.Orientation != "" && xm.Orientation != "orthogonal" {
+		return nil, fmt.Errorf("tmx: unsupported map orientation %q", xm.Orientation)
+	}
+	if xm.Infinite {
+		return nil, fmt.Errorf("tmx: infinite maps are not supported")
+	}
+	geom := grid.Geometry{
+		CellWidth:  xm.TileWidth,
+		CellHeight: xm.TileHeight,
+	}
+	m = &Map{
+		LayeredMap: grid.NewLayeredMap(xm.Width, xm.Height, geom),
+	}
+
+	// Load tile sets.
+	for _, xts := range xm.TileSets {
+		ts, err := loadTileSet(xts, dir)
+		if err != nil {
+			return nil, err
+		}
+		m.TileSets = append(m.TileSets, ts)
+	}
+
+	// Decode tile layers.
+	for _, xl := range xm.Layers {
+		err = m.decodeLayer(xl)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// decodeLayer decodes the provided tile layer and adds it on top of the
+// existing layers of the map.
+func (m *Map) decodeLayer(xl xmlLayer) error {
+	if xl.Width != m.Cols() || xl.Height != m.Rows() {
+		return fmt.Errorf("tmx: layer %q: dimensions %dx%d differ from map dimensions %dx%d", xl.Name, xl.Width, xl.Height, m.Cols(), m.Rows())
+	}
+	gids, err := xl.Data.decode(xl.Width * xl.Height)
+	if err != nil {
+		return fmt.Errorf("tmx: layer %q: %v", xl.Name, err)
+	}
+	l := m.AddLayer(xl.Name)
+	if xl.Opacity != nil {
+		l.Opacity = *xl.Opacity
+	}
+	if xl.Visible != nil {
+		l.Visible = *xl.Visible
+	}
+	// Layer data is stored in row-major order whereas the cells of grid.Map are
+	// indexed by column first.
+	for i, gid := range gids {
+		col := i % xl.Width
+		row := i / xl.Width
+		l.Cells[col][row] = tileID(gid)
+	}
+	return nil
+}
+
+// xmlMap is the XML representation of a TMX map.
+type xmlMap struct {
+	Orientation string       `xml:"orientation,attr"`
+	Width       int          `xml:"width,attr"`
+	Height      int          `xml:"height,attr"`
+	TileWidth   int          `xml:"tilewidth,attr"`
+	TileHeight  int          `xml:"tileheight,attr"`
+	Infinite    bool         `xml:"infinite,attr"`
+	TileSets    []xmlTileSet `xml:"tileset"`
+	Layers      []xmlLayer   `xml:"layer"`
+}
+
+// xmlLayer is the XML representation of a TMX tile layer.
+type xmlLayer struct {
+	Name    string   `xml:"name,attr"`
+	Width   int      `xml:"width,attr"`
+	Height  int      `xml:"height,attr"`
+	Opacity *float64 `xml:"opacity,attr"`
+	Visible *bool    `xml:"visible,attr"`
+	Data    xmlData  `xml:"data"`
+}
@@ -3,94 +3,309 @@ package view
 
 import (
 	"image"
+	"math"
 
 	"github.com/mewmew/pgg/grid"
 )
 
+// An Edge specifies how a view behaves at the edges of the world.
+type Edge int
+
+// Edge behaviours.
+const (
+	// Clamp keeps the view within the world. Worlds smaller than the view are
+	// anchored at the top left of the view.
+	Clamp Edge = iota
+	// Center keeps the view within the world. Worlds smaller than the view are
+	// centered within the view, leaving letterbox borders.
+	Center
+	// Unclamped allows the view to scroll past the edges of the world.
+	Unclamped
+)
+
 // A View is a visible portion of the screen.
 type View struct {
 	// The width and height of the view.
 	Width, Height int
-	// The width and height of the view in number of columns and rows
-	// respectively.
-	cols, rows int
-	// The pixel offset between the top left point of the world and the view.
+	// The minimum and maximum zoom factors of the view; or zero if unlimited.
+	MinZoom, MaxZoom float64
+	// The behaviour of the view at the edges of the world; changes take effect
+	// on the next move of the view.
+	Edge Edge
+	// The axes along which the world wraps around; typically the Wrap of the
+	// viewed map. The view is never clamped along wrapping axes.
+	Wrap grid.Wrap
+	// The zoom factor of the view; screen pixels per world pixel.
+	zoom float64
+	// The bottom right point of the world.
+	end image.Point
+	// The cell geometry of the viewed map.
+	geom grid.Geometry
+	// The pixel offset between the top left point of the world and the view;
+	// negative if the view extends past the top or left edge of the world.
 	off image.Point
-	// The maximum valid pixel offset of the view.
-	max image.Point
 }
 
 // NewView returns a new view of the specified dimensions. The top left point
 // of the world is assumed to be located at (0, 0) and the bottom right point of
-// the world is specified by end.
-func NewView(width, height int, end image.Point) (v *View) {
+// the world is specified by end. The cell dimensions of the viewed map are
+// specified by geom.
+func NewView(width, height int, end image.Point, geom grid.Geometry) (v *View) {
 	v = &View{
 		Width:  width,
 		Height: height,
-		cols:   width / grid.CellWidth,
-		rows:   height / grid.CellHeight,
-		max:    end.Sub(image.Pt(width+1, height+1)),
+		zoom:   1,
+		end:    end,
+		geom:   geom,
 	}
 	return v
 }
 
+// Geometry returns the cell geometry of the viewed map.
+func (v *View) Geometry() grid.Geometry {
+	return v.geom
+}
+
+// Offset returns the pixel offset between the top left point of the world and
+// the view.
+func (v *View) Offset() image.Point {
+	return v.off
+}
+
+// Size returns the width and height of the world visible through the view, in
+// world pixels. Partially visible world pixels are included.
+func (v *View) Size() image.Point {
+	w := int(math.Ceil(float64(v.Width) / v.zoom))
+	h := int(math.Ceil(float64(v.Height) / v.zoom))
+	return image.Pt(w, h)
+}
+
+// Bounds returns the world pixel rectangle visible through the view. It may
+// extend past the edges of the world.
+func (v *View) Bounds() image.Rectangle {
+	return image.Rectangle{Max: v.Size()}.Add(v.off)
+}
+
+// World returns the world pixel bounds of the viewed world.
+func (v *View) World() image.Rectangle {
+	return image.Rectangle{Max: v.end}
+}
+
+// Viewport returns the screen rectangle of the view in which the world is
+// visible; it excludes the letterbox borders around worlds smaller than the
+// view and the area past the edges of the world.
+func (v *View) Viewport() image.Rectangle {
+	screen := image.Rect(0, 0, v.Width, v.Height)
+	world := v.World()
+	r := image.Rectangle{Min: v.WorldToScreen(world.Min), Max: v.WorldToScreen(world.Max)}
+	if v.Wrap&grid.WrapHorizontal != 0 {
+		r.Min.X, r.Max.X = screen.Min.X, screen.Max.X
+	}
+	if v.Wrap&grid.WrapVertical != 0 {
+		r.Min.Y, r.Max.Y = screen.Min.Y, screen.Max.Y
+	}
+	return r.Intersect(screen)
+}
+
+// Zoom returns the zoom factor of the view; the number of screen pixels per
+// world pixel.
+func (v *View) Zoom() float64 {
+	return v.zoom
+}
+
+// SetZoom sets the zoom factor of the view, keeping the world pixel at the
+// center of the view in place. The zoom factor is clamped to the minimum and
+// maximum zoom factors of the view.
+func (v *View) SetZoom(zoom float64) {
+	v.ZoomAt(zoom, image.Pt(v.Width/2, v.Height/2))
+}
+
+// ZoomAt sets the zoom factor of the view, keeping the world pixel at the
+// screen point p in place, such as the world pixel under the mouse cursor. The
+// zoom factor is clamped to the minimum and maximum zoom factors of the view.
+func (v *View) ZoomAt(zoom float64, p image.Point) {
+	if v.MinZoom > 0 && zoom < v.MinZoom {
+		zoom = v.MinZoom
+	}
+	if v.MaxZoom > 0 && zoom > v.MaxZoom {
+		zoom = v.MaxZoom
+	}
+	if zoom <= 0 {
+		return
+	}
+	// World point at p, with sub-pixel precision.
+	wx := float64(v.off.X) + float64(p.X)/v.zoom
+	wy := float64(v.off.Y) + float64(p.Y)/v.zoom
+	v.zoom = zoom
+	off := image.Pt(int(math.Floor(wx-float64(p.X)/zoom+0.5)), int(math.Floor(wy-float64(p.Y)/zoom+0.5)))
+	v.MoveTo(off)
+}
+
+// ScreenToWorld converts the screen point p, relative to the top left point of
+// the view, to a world pixel.
+func (v *View) ScreenToWorld(p image.Point) image.Point {
+	x := int(math.Floor(float64(p.X) / v.zoom))
+	y := int(math.Floor(float64(p.Y) / v.zoom))
+	return image.Pt(x, y).Add(v.off)
+}
+
+// WorldToScreen converts the world pixel p to a screen point, relative to the
+// top left point of the view.
+func (v *View) WorldToScreen(p image.Point) image.Point {
+	p = p.Sub(v.off)
+	x := int(math.Floor(float64(p.X) * v.zoom))
+	y := int(math.Floor(float64(p.Y) * v.zoom))
+	return image.Pt(x, y)
+}
+
+// Pick returns the location of the cell at the screen point p. On wrapping
+// worlds, the location is normalized.
+func (v *View) Pick(p image.Point) grid.Location {
+	loc := v.geom.Pick(v.ScreenToWorld(p))
+	if v.Wrap != 0 {
+		cols, rows := v.dims()
+		loc, _ = v.Wrap.Normalize(loc, cols, rows)
+	}
+	return loc
+}
+
+// CellPoint returns the screen point of the top left pixel of the cell at loc.
+// On wrapping worlds, the copy of the cell nearest to the center of the view is
+// located.
+func (v *View) CellPoint(loc grid.Location) image.Point {
+	return v.WorldToScreen(v.geom.Pixel(v.nearest(loc)))
+}
+
+// CellRect returns the screen rectangle of the cell at loc, which may extend
+// beyond the view for partially visible cells. On wrapping worlds, the copy of
+// the cell nearest to the center of the view is located.
+func (v *View) CellRect(loc grid.Location) image.Rectangle {
+	bounds := v.geom.CellBounds(v.nearest(loc))
+	return image.Rectangle{Min: v.WorldToScreen(bounds.Min), Max: v.WorldToScreen(bounds.Max)}
+}
+
+// Visible returns true if the cell at loc is at least partially visible through
+// the view. On wrapping worlds, the copy of the cell nearest to the center of
+// the view is considered.
+func (v *View) Visible(loc grid.Location) bool {
+	return v.geom.CellBounds(v.nearest(loc)).Overlaps(v.Bounds())
+}
+
+// nearest returns the location of the copy of the cell at loc nearest to the
+// center of the view, on wrapping worlds.
+func (v *View) nearest(loc grid.Location) grid.Location {
+	if v.Wrap == 0 {
+		return loc
+	}
+	bounds := v.Bounds()
+	center := v.geom.Pick(bounds.Min.Add(bounds.Size().Div(2)))
+	cols, rows := v.dims()
+	dcol, drow := v.Wrap.Delta(center, loc, cols, rows)
+	return grid.Loc(center.Col+dcol, center.Row+drow)
+}
+
+// dims returns the number of columns and rows of the viewed world.
+func (v *View) dims() (cols, rows int) {
+	return v.end.X / v.geom.CellWidth, v.end.Y / v.geom.CellHeight
+}
+
+// Range returns the inclusive range of cells which are at least partially
+// visible through the view.
+func (v *View) Range() (min, max grid.Location) {
+	return v.geom.Range(v.Bounds())
+}
+
 // Move moves the view based on the provided delta offset.
 func (v *View) Move(delta image.Point) {
-	off := v.off.Add(delta)
-	// TODO(u): consider creating a geom.Clamp function to encapsulate this
-	// behaviour. It would have the following function definition:
-	//    func Clamp(p, min, max image.Point) image.Point
-	if off.X < 0 {
-		off.X = 0
+	v.MoveTo(v.off.Add(delta))
+}
+
+// MoveTo moves the view to the provided pixel offset between the top left
+// point of the world and the view. The offset is adjusted as specified by the
+// edge behaviour of the view, and wrapped around the wrapping axes of the world.
+func (v *View) MoveTo(off image.Point) {
+	// Maximum offset which keeps the view within the world; negative if the
+	// world is smaller than the view.
+	max := v.end.Sub(v.Size())
+	switch {
+	case v.Wrap&grid.WrapHorizontal != 0:
+		off.X = mod(off.X, v.end.X)
+	case v.Edge != Unclamped:
+		off.X = clamp(off.X, max.X, v.Edge)
+	}
+	switch {
+	case v.Wrap&grid.WrapVertical != 0:
+		off.Y = mod(off.Y, v.end.Y)
+	case v.Edge != Unclamped:
+		off.Y = clamp(off.Y, max.Y, v.Edge)
 	}
-	if off.Y < 0 {
-		off.Y = 0
+	v.off = off
+}
+
+// mod returns a modulo n in the range [0, n); or a if n <= 0.
+func mod(a, n int) int {
+	if n <= 0 {
+		return a
 	}
-	if off.X > v.max.X {
-		off.X = v.max.X
+	a %= n
+	if a < 0 {
+		a += n
 	}
-	if off.Y > v.max.Y {
-		off.Y = v.max.Y
+	return a
+}
+
+// clamp clamps the offset off along one axis to the range [0, max], as
+// specified by the edge behaviour. A negative max indicates that the world is
+// smaller than the view along the axis.
+func clamp(off, max int, edge Edge) int {
+	switch {
+	case max < 0 && edge == Center:
+		return max / 2
+	case max < 0, off < 0:
+		return 0
+	case off > max:
+		return max
 	}
-	v.off = off
+	return off
 }
 
-// Col returns the top left column visible through the view.
+// Col returns the left-most column which is at least partially visible through
+// the view. It may be located outside of the map.
 func (v *View) Col() int {
-	return v.off.X / grid.CellWidth
+	min, _ := v.Range()
+	return min.Col
 }
 
-// Row returns the top left row visible through the view.
+// Row returns the top-most row which is at least partially visible through the
+// view. It may be located outside of the map.
 func (v *View) Row() int {
-	return v.off.Y / grid.CellHeight
+	min, _ := v.Range()
+	return min.Row
 }
 
-// Cols returns the number of columns visible through the view.
+// Cols returns the number of columns which are at least partially visible
+// through the view, starting at Col. Callers must clamp or wrap the columns to
+// the map.
 func (v *View) Cols() int {
-	if v.off.X != 0 {
-		// TODO(u): verify that views with a width of `n*grid.CellWidth + r`
-		// don't cause an index overflow in the draw loop logic.
-		return v.cols + 1
-	}
-	return v.cols
+	min, max := v.Range()
+	return max.Col - min.Col + 1
 }
 
-// Rows returns the number of rows visible through the view.
+// Rows returns the number of rows which are at least partially visible through
+// the view, starting at Row. Callers must clamp or wrap the rows to the map.
 func (v *View) Rows() int {
-	if v.off.Y != 0 {
-		// TODO(u): verify that views with a height of `n*grid.CellHeight + r`
-		// don't cause an index overflow in the draw loop logic.
-		return v.rows + 1
-	}
-	return v.rows
+	min, max := v.Range()
+	return max.Row - min.Row + 1
 }
 
-// X returns the x offset to the grid columns visible through the view.
+// X returns the x offset, in world pixels, between the left edge of column Col
+// and the left edge of the view.
 func (v *View) X() int {
-	return v.off.X % grid.CellWidth
+	return v.off.X - v.Col()*v.geom.CellWidth
 }
 
-// Y returns the y offset to the grid rows visible through the view.
+// Y returns the y offset, in world pixels, between the top edge of row Row and
+// the top edge of the view.
 func (v *View) Y() int {
-	return v.off.Y % grid.CellHeight
+	return v.off.Y - v.Row()*v.geom.CellHeight
 }
@@ -0,0 +1,105 @@
+package view
+
+import (
+	"image"
+	"testing"
+
+	"github.com/mewmew/pgg/grid"
+)
+
+// geom is the cell geometry of the viewed maps.
+var geom = grid.Geometry{CellWidth: 32, CellHeight: 32}
+
+// newView returns a new view of the specified dimensions and edge behaviour,
+// of a world with the specified number of columns and rows.
+func newView(width, height, cols, rows int, edge Edge) *View {
+	v := NewView(width, height, geom.Size(cols, rows), geom)
+	v.Edge = edge
+	return v
+}
+
+func TestMoveTo(t *testing.T) {
+	golden := []struct {
+		name          string
+		width, height int
+		cols, rows    int
+		edge          Edge
+		off           image.Point
+		want          image.Point
+	}{
+		// World larger than the view; view not a multiple of the cell size.
+		{name: "clamp inside", width: 100, height: 70, cols: 10, rows: 10, edge: Clamp, off: image.Pt(50, 60), want: image.Pt(50, 60)},
+		{name: "clamp top left", width: 100, height: 70, cols: 10, rows: 10, edge: Clamp, off: image.Pt(-5, -7), want: image.Pt(0, 0)},
+		{name: "clamp bottom right", width: 100, height: 70, cols: 10, rows: 10, edge: Clamp, off: image.Pt(1000, 1000), want: image.Pt(220, 250)},
+		{name: "center bottom right", width: 100, height: 70, cols: 10, rows: 10, edge: Center, off: image.Pt(1000, 1000), want: image.Pt(220, 250)},
+		{name: "unclamped outside", width: 100, height: 70, cols: 10, rows: 10, edge: Unclamped, off: image.Pt(-5, 1000), want: image.Pt(-5, 1000)},
+		// World smaller than the view.
+		{name: "clamp small world", width: 100, height: 70, cols: 2, rows: 1, edge: Clamp, off: image.Pt(10, 10), want: image.Pt(0, 0)},
+		{name: "center small world", width: 100, height: 70, cols: 2, rows: 1, edge: Center, off: image.Pt(10, 10), want: image.Pt(-18, -19)},
+		{name: "unclamped small world", width: 100, height: 70, cols: 2, rows: 1, edge: Unclamped, off: image.Pt(10, 10), want: image.Pt(10, 10)},
+		// World smaller than the view along one axis only.
+		{name: "center narrow world", width: 100, height: 70, cols: 2, rows: 10, edge: Center, off: image.Pt(10, 1000), want: image.Pt(-18, 250)},
+	}
+	for _, g := range golden {
+		v := newView(g.width, g.height, g.cols, g.rows, g.edge)
+		v.MoveTo(g.off)
+		if got := v.Offset(); got != g.want {
+			t.Errorf("%s: offset mismatch; expected %v, got %v", g.name, g.want, got)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	golden := []struct {
+		name          string
+		width, height int
+		cols, rows    int
+		edge          Edge
+		off           image.Point
+		min, max      grid.Location
+	}{
+		{name: "multiple of cell size", width: 96, height: 64, cols: 10, rows: 10, off: image.Pt(0, 0), min: grid.Loc(0, 0), max: grid.Loc(2, 1)},
+		{name: "top left edge", width: 100, height: 70, cols: 10, rows: 10, off: image.Pt(0, 0), min: grid.Loc(0, 0), max: grid.Loc(3, 2)},
+		{name: "partial cells", width: 100, height: 70, cols: 10, rows: 10, off: image.Pt(31, 31), min: grid.Loc(0, 0), max: grid.Loc(4, 3)},
+		{name: "bottom right edge", width: 100, height: 70, cols: 10, rows: 10, off: image.Pt(1000, 1000), min: grid.Loc(6, 7), max: grid.Loc(9, 9)},
+		{name: "center small world", width: 100, height: 70, cols: 2, rows: 1, edge: Center, min: grid.Loc(-1, -1), max: grid.Loc(2, 1)},
+	}
+	for _, g := range golden {
+		v := newView(g.width, g.height, g.cols, g.rows, g.edge)
+		v.MoveTo(g.off)
+		min, max := v.Range()
+		if min != g.min || max != g.max {
+			t.Errorf("%s: range mismatch; expected %v-%v, got %v-%v", g.name, g.min, g.max, min, max)
+		}
+	}
+}
+
+func TestVisible(t *testing.T) {
+	golden := []struct {
+		name    string
+		off     image.Point
+		loc     grid.Location
+		visible bool
+		rect    image.Rectangle
+	}{
+		{name: "top left cell", off: image.Pt(0, 0), loc: grid.Loc(0, 0), visible: true, rect: image.Rect(0, 0, 32, 32)},
+		{name: "right edge partial", off: image.Pt(0, 0), loc: grid.Loc(3, 2), visible: true, rect: image.Rect(96, 64, 128, 96)},
+		{name: "past right edge", off: image.Pt(0, 0), loc: grid.Loc(4, 0), visible: false, rect: image.Rect(128, 0, 160, 32)},
+		{name: "past bottom edge", off: image.Pt(0, 0), loc: grid.Loc(0, 3), visible: false, rect: image.Rect(0, 96, 32, 128)},
+		{name: "left edge partial", off: image.Pt(31, 0), loc: grid.Loc(0, 0), visible: true, rect: image.Rect(-31, 0, 1, 32)},
+		{name: "bottom right cell", off: image.Pt(1000, 1000), loc: grid.Loc(9, 9), visible: true, rect: image.Rect(68, 38, 100, 70)},
+		{name: "top left partial at end", off: image.Pt(1000, 1000), loc: grid.Loc(6, 7), visible: true, rect: image.Rect(-28, -26, 4, 6)},
+		{name: "past left edge at end", off: image.Pt(1000, 1000), loc: grid.Loc(5, 7), visible: false, rect: image.Rect(-60, -26, -28, 6)},
+		{name: "past top edge at end", off: image.Pt(1000, 1000), loc: grid.Loc(6, 6), visible: false, rect: image.Rect(-28, -58, 4, -26)},
+	}
+	for _, g := range golden {
+		v := newView(100, 70, 10, 10, Clamp)
+		v.MoveTo(g.off)
+		if got := v.Visible(g.loc); got != g.visible {
+			t.Errorf("%s: visibility mismatch of %v; expected %v, got %v", g.name, g.loc, g.visible, got)
+		}
+		if got := v.CellRect(g.loc); got != g.rect {
+			t.Errorf("%s: cell rectangle mismatch of %v; expected %v, got %v", g.name, g.loc, g.rect, got)
+		}
+	}
+}